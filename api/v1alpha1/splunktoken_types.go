@@ -29,13 +29,49 @@ type SplunkTokenSpec struct {
 	DefaultIndex string `json:"defaultIndex,omitempty"`
 	// AllowedIndexes is a list of other indexes that this token is allowed to send logs to.
 	AllowedIndexes []string `json:"allowedIndexes,omitempty"`
+	// Sourcetype is the default sourcetype assigned to events sent with this token.
+	Sourcetype string `json:"sourcetype,omitempty"`
+	// Description is shown with the token in Splunk to explain where it came from.
+	Description string `json:"description,omitempty"`
+	// Disabled stops Splunk from accepting events sent with the HEC token without deleting it.
+	Disabled bool `json:"disabled,omitempty"`
+	// SplunkInstance is the name of the Splunk instance the HEC token is created on.
+	// The operator's configured instance is used when empty.
+	SplunkInstance string `json:"splunkInstance,omitempty"`
 }
 
 // SplunkTokenStatus defines the observed state of SplunkToken.
 // +k8s:openapi-gen=true
 type SplunkTokenStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// TokenIssuedAt is the time the current HEC token value was issued and stored in the Secret.
+	TokenIssuedAt *metav1.Time `json:"tokenIssuedAt,omitempty"`
+	// LastRotationTime is the time the HEC token was last rotated without recreating the SplunkToken.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// PreviousTokens lists the most recently rotated HEC tokens, oldest first.
+	// Tokens that have not been deleted from the Splunk instance are deleted by a later reconcile.
+	PreviousTokens []PreviousToken `json:"previousTokens,omitempty"`
+	// Disabled is true once the HEC token has been disabled on the Splunk instance.
+	Disabled bool `json:"disabled,omitempty"`
+	// SplunkInstance is the name of the Splunk instance holding the HEC token.
+	SplunkInstance string `json:"splunkInstance,omitempty"`
+	// CollectorURI is the HEC endpoint the token Secret points the forwarder at.
+	CollectorURI string `json:"collectorURI,omitempty"`
+}
+
+// PreviousToken is a HEC token that was replaced by a rotation.
+// +k8s:openapi-gen=true
+type PreviousToken struct {
+	// Name is the name of the rotated HEC token on the Splunk instance.
+	Name string `json:"name"`
+	// RotatedAt is the time the HEC token was rotated.
+	RotatedAt metav1.Time `json:"rotatedAt"`
+	// IssuedAt is the time the rotated HEC token value was issued.
+	IssuedAt *metav1.Time `json:"issuedAt,omitempty"`
+	// ValueHash is the hex encoded SHA-256 hash of the rotated HEC token value, which identifies
+	// the rotated token among the tokens issued under the same name.
+	ValueHash string `json:"valueHash,omitempty"`
+	// Deleted is true once the HEC token has been deleted from the Splunk instance.
+	Deleted bool `json:"deleted,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -24,13 +24,33 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviousToken) DeepCopyInto(out *PreviousToken) {
+	*out = *in
+	in.RotatedAt.DeepCopyInto(&out.RotatedAt)
+	if in.IssuedAt != nil {
+		in, out := &in.IssuedAt, &out.IssuedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviousToken.
+func (in *PreviousToken) DeepCopy() *PreviousToken {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviousToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SplunkToken) DeepCopyInto(out *SplunkToken) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkToken.
@@ -106,6 +126,21 @@ func (in *SplunkTokenSpec) DeepCopy() *SplunkTokenSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SplunkTokenStatus) DeepCopyInto(out *SplunkTokenStatus) {
 	*out = *in
+	if in.TokenIssuedAt != nil {
+		in, out := &in.TokenIssuedAt, &out.TokenIssuedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousTokens != nil {
+		in, out := &in.PreviousTokens, &out.PreviousTokens
+		*out = make([]PreviousToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkTokenStatus.
@@ -14,12 +14,63 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/openshift/splunk-token-operator/api/v1alpha1.PreviousToken":     schema_openshift_splunk_token_operator_api_v1alpha1_PreviousToken(ref),
 		"github.com/openshift/splunk-token-operator/api/v1alpha1.SplunkToken":       schema_openshift_splunk_token_operator_api_v1alpha1_SplunkToken(ref),
 		"github.com/openshift/splunk-token-operator/api/v1alpha1.SplunkTokenSpec":   schema_openshift_splunk_token_operator_api_v1alpha1_SplunkTokenSpec(ref),
 		"github.com/openshift/splunk-token-operator/api/v1alpha1.SplunkTokenStatus": schema_openshift_splunk_token_operator_api_v1alpha1_SplunkTokenStatus(ref),
 	}
 }
 
+func schema_openshift_splunk_token_operator_api_v1alpha1_PreviousToken(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PreviousToken is a HEC token that was replaced by a rotation.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the rotated HEC token on the Splunk instance.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rotatedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RotatedAt is the time the HEC token was rotated.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"issuedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IssuedAt is the time the rotated HEC token value was issued.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"valueHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ValueHash is the hex encoded SHA-256 hash of the rotated HEC token value, which identifies the rotated token among the tokens issued under the same name.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deleted is true once the HEC token has been deleted from the Splunk instance.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "rotatedAt"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_openshift_splunk_token_operator_api_v1alpha1_SplunkToken(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -104,6 +155,34 @@ func schema_openshift_splunk_token_operator_api_v1alpha1_SplunkTokenSpec(ref com
 							},
 						},
 					},
+					"sourcetype": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Sourcetype is the default sourcetype assigned to events sent with this token.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Description is shown with the token in Splunk to explain where it came from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"disabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled stops Splunk from accepting events sent with the HEC token without deleting it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"splunkInstance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SplunkInstance is the name of the Splunk instance the HEC token is created on. The operator's configured instance is used when empty.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"name"},
 			},
@@ -117,7 +196,58 @@ func schema_openshift_splunk_token_operator_api_v1alpha1_SplunkTokenStatus(ref c
 			SchemaProps: spec.SchemaProps{
 				Description: "SplunkTokenStatus defines the observed state of SplunkToken.",
 				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"tokenIssuedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TokenIssuedAt is the time the current HEC token value was issued and stored in the Secret.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastRotationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastRotationTime is the time the HEC token was last rotated without recreating the SplunkToken.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"previousTokens": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PreviousTokens lists the most recently rotated HEC tokens, oldest first. Tokens that have not been deleted from the Splunk instance are deleted by a later reconcile.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/openshift/splunk-token-operator/api/v1alpha1.PreviousToken"),
+									},
+								},
+							},
+						},
+					},
+					"disabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disabled is true once the HEC token has been disabled on the Splunk instance.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"splunkInstance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SplunkInstance is the name of the Splunk instance holding the HEC token.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"collectorURI": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CollectorURI is the HEC endpoint the token Secret points the forwarder at.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/openshift/splunk-token-operator/api/v1alpha1.PreviousToken", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -17,10 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -37,11 +42,13 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
-	"github.com/BurntSushi/toml"
 	splunktokenv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
 	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/audit"
 	"github.com/openshift/splunk-token-operator/internal/controller"
 	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+	"github.com/openshift/splunk-token-operator/internal/splunk/faketokens"
+	webhookv1alpha1 "github.com/openshift/splunk-token-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -66,9 +73,18 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
 	var tlsOpts []func(*tls.Config)
 	var configFile string
-	flag.StringVar(&configFile, "config", config.ConfigFile, "The path to the config file for the operator.")
+	var fakeTokensFile string
+	var validateConfig bool
+	var overrides config.Overrides
+	flag.StringVar(&configFile, "config", config.ConfigPath,
+		"The path to the config file for the operator, or a directory of *.toml files to merge in lexical order.")
+	flag.StringVar(&overrides.SplunkInstance, "splunk-instance", "",
+		"If set, overrides the Splunk instance configured in the config file.")
+	flag.BoolVar(&validateConfig, "validate-config", false,
+		"If set, validates the config file, prints a summary of its settings and exits without starting the manager.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -86,6 +102,11 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"If set, the validating webhook protecting SplunkTokens from deletion is served. "+
+			"Use --enable-webhooks=false to run without a webhook server certificate, e.g. locally.")
+	flag.StringVar(&fakeTokensFile, "fake-splunk-tokens", "",
+		"If set, HEC tokens are stored in this local file instead of Splunk, for testing without a Splunk instance.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -94,6 +115,10 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if validateConfig {
+		os.Exit(checkConfig(configFile, overrides))
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -207,28 +232,163 @@ func main() {
 		os.Exit(1)
 	}
 
-	var splunkConfig config.Splunk
-	if _, err := toml.DecodeFile(configFile, &splunkConfig); err != nil {
-		setupLog.Error(err, "error parsing operator config", "config file", configFile)
-		os.Exit(1)
+	splunkConfig, err := config.Load(configFile)
+	if err == nil {
+		overrides.Apply(&splunkConfig)
+		err = splunkConfig.Validate()
 	}
-
-	splunkApiKey := os.Getenv(config.ApiTokenEnvKey)
-	splunkClient, err := splunkapi.NewClient(splunkConfig.SplunkInstance, splunkApiKey)
+	configCheck := config.LoadCheck(err)
 	if err != nil {
-		setupLog.Error(err, "error creating Splunk API client")
-		os.Exit(1)
+		setupLog.Error(err, "invalid operator config", "config file", configFile)
+		// keep serving the probe endpoints so the invalid config is reported by the readiness check
+		addHealthChecks(mgr, configCheck)
+		startManager(mgr)
+		return
 	}
 
-	if err := (&controller.SplunkTokenReconciler{
+	var tokenManager splunkapi.TokenManager
+	instanceManagers := map[string]splunkapi.TokenManager{}
+	if fakeTokensFile != "" {
+		setupLog.Info("storing HEC tokens locally instead of in Splunk", "file", fakeTokensFile)
+		tokenManager, err = faketokens.New(fakeTokensFile)
+		if err != nil {
+			setupLog.Error(err, "error loading fake Splunk tokens")
+			os.Exit(1)
+		}
+	} else {
+		splunkApiKey := os.Getenv(config.ApiTokenEnvKey)
+		clientOptions := []splunkapi.ClientOption{
+			splunkapi.WithFieldNames(splunkConfig.ACS.FieldNames),
+			splunkapi.WithHeaders(splunkConfig.ACS.Headers),
+			splunkapi.WithCircuitBreaker(splunkConfig.ACS.CircuitBreakerThreshold, splunkConfig.ACS.CircuitBreakerCooldown),
+			splunkapi.WithMaxErrorBodySize(splunkConfig.ACS.MaxErrorBodySize),
+			splunkapi.WithMetadataFields(splunkConfig.ACS.MetadataFields),
+			splunkapi.WithUpdateMethod(splunkConfig.ACS.UpdateMethod),
+			splunkapi.WithRequestIDHeader(splunkConfig.ACS.RequestIDHeader),
+			splunkapi.WithRequestTimeout(splunkConfig.ACS.RequestTimeout),
+			splunkapi.WithMinTLSVersion(config.TLSVersions[splunkConfig.ACS.MinTLSVersion]),
+			splunkapi.WithStrictDecoding(splunkConfig.ACS.StrictDecoding),
+			splunkapi.WithJWTValidation(splunkConfig.ACS.ValidateJWT),
+			splunkapi.WithRetryableErrorCodes(splunkConfig.ACS.RetryableErrorCodes, splunkConfig.ACS.ErrorCodeRetries),
+			splunkapi.WithMaintenanceErrorCodes(splunkConfig.ACS.MaintenanceErrorCodes),
+			splunkapi.WithDeleteConsistencyWindow(splunkConfig.ACS.DeleteConsistencyWindow, splunkConfig.ACS.DeleteConsistencyInterval),
+		}
+		if splunkConfig.ACS.EnterpriseURL != "" {
+			setupLog.Info("managing HEC tokens through the Splunk Enterprise REST API", "url", splunkConfig.ACS.EnterpriseURL)
+			clientOptions = append(clientOptions, splunkapi.WithEnterpriseAPI(splunkConfig.ACS.EnterpriseURL))
+		} else if splunkConfig.ACS.ValidateIndexes {
+			clientOptions = append(clientOptions, splunkapi.WithIndexValidation(splunkConfig.ACS.IndexCacheTTL))
+		}
+		splunkClient, err := splunkapi.NewClient(splunkConfig.SplunkInstance, splunkApiKey,
+			withInstanceLimits(clientOptions, splunkConfig.ACS, splunkConfig.SplunkInstance)...)
+		if err != nil {
+			setupLog.Error(err, "error creating Splunk API client")
+			os.Exit(1)
+		}
+		if splunkConfig.ACS.StartupAccessCheck {
+			checkCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := splunkClient.CheckAccess(checkCtx)
+			cancel()
+			if splunkapi.IsForbidden(err) {
+				setupLog.Error(err, "Splunk authentication token is not allowed to manage HEC tokens, check its scope")
+				os.Exit(1)
+			} else if err != nil {
+				setupLog.Error(err, "unable to verify Splunk authentication token")
+				os.Exit(1)
+			}
+		}
+		tokenManager = splunkClient
+
+		if splunkConfig.ACS.EnterpriseURL == "" {
+			for _, instance := range splunkConfig.SplunkInstances {
+				instanceClient, err := splunkapi.NewClient(instance, splunkApiKey,
+					withInstanceLimits(clientOptions, splunkConfig.ACS, instance)...)
+				if err != nil {
+					setupLog.Error(err, "error creating Splunk API client", "instance", instance)
+					os.Exit(1)
+				}
+				instanceManagers[instance] = instanceClient
+			}
+		}
+	}
+
+	if err := mgr.AddMetricsServerExtraHandler(controller.StatePath, &controller.StateHandler{
 		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
 		SplunkConfig: splunkConfig.General,
-		SplunkApi:    splunkClient,
+	}); err != nil {
+		setupLog.Error(err, "unable to add state handler to metrics server")
+		os.Exit(1)
+	}
+
+	var auditLogger *audit.Logger
+	if splunkConfig.AuditLogPath != "" {
+		auditLogger, err = audit.Open(splunkConfig.AuditLogPath)
+		if err != nil {
+			setupLog.Error(err, "unable to open audit log", "path", splunkConfig.AuditLogPath)
+			os.Exit(1)
+		}
+	}
+
+	var activitySummary *controller.ActivitySummary
+	if splunkConfig.SummaryInterval > 0 {
+		activitySummary = &controller.ActivitySummary{Interval: splunkConfig.SummaryInterval}
+		if err := mgr.Add(activitySummary); err != nil {
+			setupLog.Error(err, "unable to add activity summary to manager")
+			os.Exit(1)
+		}
+	}
+
+	if splunkConfig.TokenAgeInterval > 0 {
+		if err := mgr.Add(&controller.TokenAgeSweep{
+			Client:       mgr.GetClient(),
+			SplunkConfig: splunkConfig.General,
+			Interval:     splunkConfig.TokenAgeInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add token age sweep to manager")
+			os.Exit(1)
+		}
+	}
+
+	var tokenQuota *controller.TokenQuota
+	if splunkConfig.TokenQuota > 0 {
+		lister, ok := tokenManager.(splunkapi.TokenLister)
+		if !ok {
+			setupLog.Error(errors.New("token manager cannot list tokens"), "unable to count HEC tokens for the token quota")
+			os.Exit(1)
+		}
+		tokenQuota = &controller.TokenQuota{
+			Lister:       lister,
+			Quota:        splunkConfig.TokenQuota,
+			WarningRatio: splunkConfig.TokenQuotaWarningRatio,
+			Interval:     splunkConfig.TokenQuotaInterval,
+		}
+		if err := mgr.Add(tokenQuota); err != nil {
+			setupLog.Error(err, "unable to add token quota count to manager")
+			os.Exit(1)
+		}
+	}
+
+	if err := (&controller.SplunkTokenReconciler{
+		Client:          mgr.GetClient(),
+		APIReader:       mgr.GetAPIReader(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("splunktoken-controller"),
+		SplunkConfig:    splunkConfig.General,
+		SplunkApi:       tokenManager,
+		SplunkInstances: instanceManagers,
+		Summary:         activitySummary,
+		Audit:           auditLogger,
+		Quota:           tokenQuota,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SplunkToken")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err := webhookv1alpha1.SetupSplunkTokenWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SplunkToken")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -247,6 +407,21 @@ func main() {
 		}
 	}
 
+	addHealthChecks(mgr, configCheck)
+	startManager(mgr)
+}
+
+// withInstanceLimits returns the client options with the request limits of the Splunk instance.
+func withInstanceLimits(opts []splunkapi.ClientOption, acs config.ACS, instance string) []splunkapi.ClientOption {
+	limits := acs.LimitsFor(instance)
+	return append(slices.Clip(opts),
+		splunkapi.WithRateLimit(limits.RateLimit, limits.RateLimitBurst),
+		splunkapi.WithConcurrencyLimit(limits.MaxConcurrentRequests))
+}
+
+// addHealthChecks registers the manager's probes. The config check reports
+// whether the operator config loaded, separately from the readyz ping.
+func addHealthChecks(mgr ctrl.Manager, configCheck healthz.Checker) {
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -255,7 +430,34 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("config", configCheck); err != nil {
+		setupLog.Error(err, "unable to set up config check")
+		os.Exit(1)
+	}
+}
+
+// checkConfig loads and validates the config file for --validate-config and prints a summary
+// of its settings, returning the exit code of the program.
+func checkConfig(configFile string, overrides config.Overrides) int {
+	splunkConfig, err := config.Load(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing operator config %s: %s\n", configFile, err)
+		return 1
+	}
+	overrides.Apply(&splunkConfig)
+	if err := splunkConfig.WriteSummary(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing config summary: %s\n", err)
+		return 1
+	}
+	if err := splunkConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid operator config %s:\n%s\n", configFile, err)
+		return 1
+	}
+	fmt.Printf("config %s is valid\n", configFile)
+	return 0
+}
 
+func startManager(mgr ctrl.Manager) {
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
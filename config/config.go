@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/tls"
 	"time"
 )
 
@@ -9,24 +10,417 @@ const (
 	OperatorNamespace string = "openshift-splunk-token-operator"
 
 	ApiTokenEnvKey  string = "SPLUNK_API_TOKEN" // #nosec G101 -- this is not a credential
-	ConfigFile      string = "/etc/splunktoken.d/splunktoken.toml"
+	ConfigPath      string = "/etc/splunktoken.d"
 	OwnedObjectName string = "splunk-hec-token"
 	SecretDataKey   string = "outputs.conf"
+	OutputStanza    string = "httpout"
+	HECURLDataKey   string = "hec_url"
 	TokenFinalizer  string = "splunktoken.managed.openshift.io/finalizer"
+
+	// IndexRoutingDataKey and IndexRoutingStanza name the key and stanza of the index routing
+	// rules added to the token Secret with SecretIndexRouting.
+	IndexRoutingDataKey string = "index_routing.conf"
+	IndexRoutingStanza  string = "index_routing"
+
+	// TokenMetadataAnnotationPrefix marks SplunkToken annotations that are sent as HEC token
+	// metadata, e.g. splunktoken.managed.openshift.io/metadata.owner sends the owner field.
+	TokenMetadataAnnotationPrefix string = "splunktoken.managed.openshift.io/metadata."
+
+	// ManagedSecretLabel is set to "true" on every Secret the operator creates.
+	// The operator never modifies a Secret without it unless the SplunkToken controls it.
+	ManagedSecretLabel string = "splunktoken.managed.openshift.io/managed"
+
+	// RotationStrategyObject rotates a stale HEC token by deleting its SplunkToken so it is recreated.
+	// RotationStrategySecret issues a new token value and replaces only the token Secret.
+	RotationStrategyObject string = "object"
+	RotationStrategySecret string = "secret"
+
+	// MaxAgeAnnotation overrides TokenMaxAge for a single SplunkToken with a duration such as 72h.
+	// Values that are not a positive duration are ignored.
+	MaxAgeAnnotation string = "splunktoken.managed.openshift.io/max-age"
+
+	// TokenObjectName is the name of the canonical SplunkToken in a cluster's namespace.
+	TokenObjectName string = "cluster"
+
+	// DuplicateTokenPolicyIgnore, DuplicateTokenPolicyAdopt, and DuplicateTokenPolicyError are
+	// how SplunkTokens other than the canonical TokenObjectName in a namespace are handled.
+	DuplicateTokenPolicyIgnore string = "ignore"
+	DuplicateTokenPolicyAdopt  string = "adopt"
+	DuplicateTokenPolicyError  string = "error"
+
+	// LastRotatedAnnotation is set on the token Secret to the RFC 3339 time its token value was
+	// issued, so consumers can reload their forwarder when it changes.
+	LastRotatedAnnotation string = "splunktoken.managed.openshift.io/last-rotated"
+
+	// AllowDeleteAnnotation must be set to "true" on a SplunkToken before the webhook allows it to be deleted.
+	AllowDeleteAnnotation string = "splunktoken.managed.openshift.io/allow-delete"
+
+	// SecretLifecycleOwnerReference makes the SplunkToken the controller owner of its token Secret,
+	// so the garbage collector deletes the Secret with the SplunkToken.
+	// SecretLifecycleFinalizer creates token Secrets without an owner reference, so they survive
+	// their SplunkToken being deleted and recreated, and records the owning SplunkToken in the
+	// SecretOwnerAnnotation instead.
+	SecretLifecycleOwnerReference string = "owner-reference"
+	SecretLifecycleFinalizer      string = "finalizer"
+
+	// StaleSecretPolicyReissue deletes a token Secret still owned by a deleted SplunkToken of the
+	// same name and issues a new token. StaleSecretPolicyWait leaves the Secret to the garbage
+	// collector and issues a new token once it is gone.
+	StaleSecretPolicyReissue string = "reissue"
+	StaleSecretPolicyWait    string = "wait"
+
+	// SecretOwnerAnnotation is set on token Secrets to the UID of the SplunkToken that issued their
+	// token value when they are created with SecretLifecycleFinalizer.
+	SecretOwnerAnnotation string = "splunktoken.managed.openshift.io/owner-uid"
 )
 
+// TLSVersions maps the values of ACS.MinTLSVersion to TLS protocol versions.
+var TLSVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// A MaintenanceWindow is a period during which the Splunk instance is under maintenance.
+type MaintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
 type Splunk struct {
 	General `toml:"General"`
 	Classic Deployment
 	HCP     Deployment
+	ACS     ACS
 }
 
 type General struct {
 	TokenMaxAge    time.Duration
 	SplunkInstance string
+
+	// SplunkInstances lists other Splunk Cloud instances a SplunkToken may select with
+	// spec.splunkInstance. Their clients use the API key and ACS options of SplunkInstance,
+	// except for their ACS.InstanceLimits.
+	SplunkInstances []string
+	// EnterpriseHECURL is the HEC endpoint of the Splunk Enterprise instance, e.g.
+	// https://splunk.example.com:8088, written to token Secrets and status in place of the
+	// Splunk Cloud endpoint of SplunkInstance. It must be set along with ACS.EnterpriseURL.
+	EnterpriseHECURL string
+
+	// ReconcileTimeout bounds the total time of a single reconcile, including every ACS request
+	// and retry it makes. Zero means no limit.
+	ReconcileTimeout time.Duration
+	// RetryBudget bounds the total number of retries of Splunk requests in a single reconcile,
+	// across retries of ACS error codes, DeleteRetries, and delete consistency checks. Once it is
+	// spent, failing requests return their error without retrying. Zero means no bound.
+	RetryBudget int
+
+	// RotationSkewTolerance is added to TokenMaxAge before a SplunkToken is considered stale,
+	// so clock skew between the operator and the API server cannot trigger rotation early.
+	RotationSkewTolerance time.Duration
+	// RotationStrategy is how stale HEC tokens are rotated, RotationStrategyObject or
+	// RotationStrategySecret. Defaults to RotationStrategyObject when empty. With the secret
+	// strategy a token's age is measured from when its current value was issued.
+	RotationStrategy string
+	// MinRotationInterval is the minimum time between rotations of a HEC token, a safety rail
+	// against a misconfiguration or clock glitch rotating a token in a loop. Zero disables it.
+	MinRotationInterval time.Duration
+	// PreviousTokensLimit is the number of rotated HEC tokens recorded in a SplunkToken's status,
+	// so a token whose deletion was interrupted is deleted by a later reconcile. Rotated tokens are
+	// identified by the hash of their value, read from Splunk before rotating. Zero disables it.
+	PreviousTokensLimit int
+
+	// Paused stops all reconciliation for maintenance without scaling the operator down.
+	// Reconcile returns immediately without contacting Splunk or changing any resources.
+	Paused bool
+
+	// MaintenanceWindows are scheduled Splunk maintenance periods. During a window, SplunkTokens
+	// are not reconciled and are requeued for the end of the window with a SplunkMaintenance event.
+	// A request failing with one of the ACS MaintenanceErrorCodes is handled the same way, requeued
+	// after MaintenanceRequeueInterval (1 hour when zero).
+	MaintenanceWindows         []MaintenanceWindow
+	MaintenanceRequeueInterval time.Duration
+
+	// AllowedNamespaces limits reconciliation to SplunkTokens in the listed namespaces, e.g. to
+	// roll out token management gradually. Empty allows every namespace. SplunkTokens in
+	// DeniedNamespaces are never reconciled. SplunkTokens being deleted are always finalized.
+	// Both lists are independent of the namespaces the operator's RBAC allows it to read.
+	AllowedNamespaces []string
+	DeniedNamespaces  []string
+
+	// MaxTokensPerNamespace caps the number of SplunkTokens in a namespace that
+	// the operator will create HEC tokens for. Zero means no limit.
+	MaxTokensPerNamespace int
+	// DuplicateTokenPolicy is how SplunkTokens not named TokenObjectName are handled:
+	// DuplicateTokenPolicyIgnore skips them, DuplicateTokenPolicyAdopt manages them like the
+	// canonical SplunkToken in a Secret named after SecretName with their own name appended,
+	// and DuplicateTokenPolicyError skips them with a warning event.
+	// Any other value is treated as DuplicateTokenPolicyError. Empty manages every SplunkToken
+	// without an event. SplunkTokens being deleted are always finalized.
+	DuplicateTokenPolicy string
+
+	// SummaryInterval is how often a summary of tokens created, rotated,
+	// deleted, and errored is logged. Zero disables the summary.
+	SummaryInterval time.Duration
+	// TokenAgeInterval is how often the age of the oldest HEC token is measured for the
+	// splunk_token_oldest_age_ratio metric. Zero disables the measurement.
+	TokenAgeInterval time.Duration
+	// TokenQuota is the number of HEC tokens the Splunk instance allows. When set, the tokens
+	// are counted every TokenQuotaInterval (10 minutes when zero) for the splunk_token_hec_tokens
+	// and splunk_token_hec_token_quota_ratio metrics, and creating a token once the count reaches
+	// TokenQuotaWarningRatio of the quota (0.9 when zero) records a warning event.
+	// Zero disables the count.
+	TokenQuota             int
+	TokenQuotaWarningRatio float64
+	TokenQuotaInterval     time.Duration
+
+	// VerifyInterval is how often each SplunkToken with a Secret is checked against Splunk.
+	// If its HEC token was deleted directly in Splunk a new token is issued and the Secret
+	// is replaced. Zero disables verification.
+	VerifyInterval time.Duration
+
+	// UpdateIndexes makes every reconcile compare the indexes, sourcetype and description of a
+	// SplunkToken with those of its HEC token in Splunk, and update the HEC token when they
+	// differ, e.g. after the index configuration changes.
+	UpdateIndexes bool
+
+	// TokenTags are sent with every HEC token for cost attribution, e.g. businessUnit = "security".
+	// TokenTagLabels maps tag names to SplunkToken labels whose values are sent as tags, taking
+	// precedence over TokenTags. Tags are sent when HEC tokens are created, and kept in sync
+	// along with their indexes when UpdateIndexes is set.
+	TokenTags      map[string]string
+	TokenTagLabels map[string]string
+
+	// DeleteRetries is how many times a failed DeleteToken is retried while finalizing a
+	// SplunkToken before the reconcile is requeued. Retries wait DeleteRetryBackoff,
+	// doubling after each attempt (1 second when zero), unless ReconcileTimeout ends the wait.
+	// Zero disables retries.
+	DeleteRetries      int
+	DeleteRetryBackoff time.Duration
+	// ConcurrentDeletions is how many deleted SplunkTokens are finalized at the same time, e.g.
+	// while a namespace with many SplunkTokens is torn down. Other reconciles still run one at a
+	// time, and requests to Splunk remain subject to the ACS request limits.
+	// Zero finalizes one SplunkToken at a time.
+	ConcurrentDeletions int
+	// FinalizerTimeout is how long after a SplunkToken is deleted its finalizer is removed even
+	// if the HEC token cannot be deleted from Splunk, leaving the token to be deleted manually.
+	// Zero keeps the finalizer until the HEC token is deleted.
+	FinalizerTimeout time.Duration
+	// UpdateConflictRetries is how many times an update of a SplunkToken that conflicts with
+	// a concurrent change is retried against the latest version. Zero disables retries.
+	UpdateConflictRetries int
+	// LegacyFinalizers are finalizer names used by earlier versions of the operator.
+	// They are replaced with TokenFinalizer on SplunkTokens, and removed when finalizing them.
+	LegacyFinalizers []string
+
+	// DeleteSecretOnFinalize deletes the token Secret while finalizing a SplunkToken instead of
+	// leaving it to the garbage collector, so the revoked token value is removed immediately.
+	// Only Secrets managed by the operator are deleted.
+	DeleteSecretOnFinalize bool
+	// SecretLifecycle is how token Secrets are cleaned up, SecretLifecycleOwnerReference or
+	// SecretLifecycleFinalizer. Defaults to SecretLifecycleOwnerReference when empty. With the
+	// finalizer lifecycle a Secret is kept when its SplunkToken is deleted with the
+	// AllowDeleteAnnotation to rotate it, unless DeleteSecretOnFinalize is set, and is replaced once
+	// the recreated SplunkToken issues a new token, so rotating by recreating the SplunkToken never
+	// leaves the namespace without a Secret. Otherwise the Secret is deleted with its SplunkToken.
+	// Secrets created before the lifecycle changed keep their owner reference until replaced.
+	// Secrets without an owner reference are still watched, so one deleted or modified out of
+	// band is recreated or corrected.
+	SecretLifecycle string
+	// StaleSecretPolicy is how a token Secret whose owner reference points at a deleted
+	// SplunkToken of the same name is handled, e.g. after rotation recreated the SplunkToken
+	// before the garbage collector deleted its Secret. StaleSecretPolicyReissue or
+	// StaleSecretPolicyWait, and defaults to StaleSecretPolicyReissue when empty.
+	StaleSecretPolicy string
+	// MutableSecrets creates token Secrets without marking them immutable, so a change of their
+	// contents, labels or annotations, e.g. the last-rotated annotation, is patched in place instead
+	// of deleting and recreating the Secret. Existing immutable Secrets are still replaced when
+	// their contents change, while their labels and annotations are patched.
+	MutableSecrets bool
+	// ReissueEmptyTokens reissues the HEC token of a managed Secret whose token value is empty,
+	// which would otherwise be kept as is because the Secret exists.
+	ReissueEmptyTokens bool
+	// ReuseExistingTokens makes a new SplunkToken take over a HEC token that already exists in
+	// Splunk under its token name, updating the token's indexes if they differ, instead of
+	// issuing a new token. This avoids replacing the token value when a SplunkToken is recreated
+	// while its HEC token is kept, e.g. after its finalizer timed out.
+	ReuseExistingTokens bool
+	// DeleteTokenOnStoreFailure deletes a newly created HEC token from Splunk when its value
+	// cannot be stored, so the token is not left in Splunk without a Secret.
+	DeleteTokenOnStoreFailure bool
+
+	// Splunk may allow a HEC token created without indexes to write to every index.
+	// FallbackIndex, if set, is used as the default index of SplunkTokens that set no indexes.
+	// Otherwise, RequireIndex refuses to create HEC tokens for those SplunkTokens.
+	FallbackIndex string
+	RequireIndex  bool
+
+	// Index names are trimmed of surrounding whitespace before they are sent to Splunk,
+	// and duplicates are dropped. LowercaseIndexes also lowercases them.
+	LowercaseIndexes bool
+
+	// ForbiddenRequeueInterval is how long to wait before retrying a SplunkToken
+	// after Splunk rejects a request with 403 Forbidden, which usually means the
+	// authentication token lacks permission. Defaults to 30 minutes when zero.
+	ForbiddenRequeueInterval time.Duration
+	// TerminatingSecretRequeueInterval is how long to wait before checking again for a
+	// token Secret that is being deleted, or left to the garbage collector by StaleSecretPolicyWait,
+	// so it is recreated once it is gone. Defaults to 5 seconds when zero.
+	TerminatingSecretRequeueInterval time.Duration
+
+	// SecretName is the name of the token Secret. Defaults to splunk-hec-token when empty.
+	// When it changes, existing token Secrets are renamed and keep their token value.
+	SecretName string
+	// SecretDataKey is the Secret data key the outputs.conf is stored under.
+	// Defaults to outputs.conf when empty.
+	SecretDataKey string
+	// OutputStanza is the outputs.conf stanza the token is written under,
+	// to match an existing forwarding group. Defaults to httpout when empty.
+	OutputStanza string
+	// OutputSourcetype adds a sourcetype to the outputs.conf stanza, for forwarders that require one.
+	// A SplunkToken's Sourcetype takes precedence and is written even when this is empty.
+	OutputSourcetype string
+	// SecretHECURL adds the HEC endpoint URL to the token Secret under the hec_url key,
+	// for consumers that do not read outputs.conf.
+	SecretHECURL bool
+	// SecretIndexRouting adds the token's default index, allowed indexes and sourcetype to the
+	// token Secret as an index_routing stanza under the index_routing.conf key, so a forwarder
+	// reads its token and routing rules from one Secret.
+	SecretIndexRouting bool
+	// SecretType sets the type of the token Secret. Defaults to Opaque when empty.
+	SecretType string
+	// SecretLabels and SecretAnnotations are added to the token Secret's metadata.
+	SecretLabels      map[string]string
+	SecretAnnotations map[string]string
+	// SecretClusterIDLabel, if set, is a label key added to the token Secret
+	// with the HEC token name (the cluster ID) as its value.
+	SecretClusterIDLabel string
+
+	// AuditLogPath, if set, is where a JSON audit record of every HEC token creation, rotation,
+	// and deletion is appended. "-" writes the records to standard output.
+	AuditLogPath string
+
+	// MetadataConfigMapName, if set, is the name of a ConfigMap maintained in each
+	// namespace listing the non-secret metadata of its HEC tokens for discovery.
+	MetadataConfigMapName string
 }
 
 type Deployment struct {
 	DefaultIndex   string
 	AllowedIndexes []string
 }
+
+// RequestLimits throttle the requests sent to a single Splunk instance. See ACS.RateLimit.
+type RequestLimits struct {
+	RateLimit             float64
+	RateLimitBurst        int
+	MaxConcurrentRequests int
+}
+
+// LimitsFor returns the request limits of the Splunk instance.
+func (a ACS) LimitsFor(instance string) RequestLimits {
+	if limits, found := a.InstanceLimits[instance]; found {
+		return limits
+	}
+	return RequestLimits{
+		RateLimit:             a.RateLimit,
+		RateLimitBurst:        a.RateLimitBurst,
+		MaxConcurrentRequests: a.MaxConcurrentRequests,
+	}
+}
+
+// ACS configures the connection to Splunk's Admin Config Services API.
+type ACS struct {
+	// FieldNames renames SplunkTokenSpec fields in the token request body,
+	// keyed by the spec's JSON field name (e.g. defaultIndex = "default_index").
+	FieldNames map[string]string
+
+	// Headers are added to every ACS request, e.g. for tenant or gateway authentication.
+	// They cannot override the Authorization or Content-Type headers.
+	Headers map[string]string
+
+	// After CircuitBreakerThreshold consecutive connection failures, requests to
+	// ACS fail immediately until CircuitBreakerCooldown has passed.
+	// A threshold of zero disables the circuit breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// MetadataFields lists the token metadata fields ACS accepts. SplunkToken metadata
+	// annotations for any other field are ignored.
+	MetadataFields []string
+
+	// StartupAccessCheck makes the operator verify at startup that its Splunk authentication
+	// token can list HEC tokens, and exit if it cannot. The token does not need admin access,
+	// but it must be allowed to list, create, and delete HTTP Event Collector tokens through ACS.
+	StartupAccessCheck bool
+
+	// UpdateMethod is the HTTP method used to update existing tokens, PUT or PATCH
+	// depending on the ACS version. Defaults to PUT when empty.
+	UpdateMethod string
+
+	// RequestIDHeader is the ACS response header holding the request ID reported in
+	// errors and debug logs. Defaults to X-Request-Id when empty.
+	RequestIDHeader string
+
+	// ValidateIndexes makes the operator check that a token's indexes exist in Splunk before
+	// creating it. The list of indexes is cached for IndexCacheTTL.
+	ValidateIndexes bool
+	IndexCacheTTL   time.Duration
+
+	// RequestTimeout limits how long a single ACS request may take. Zero means no limit.
+	RequestTimeout time.Duration
+
+	// MinTLSVersion is the oldest TLS version, "1.2" or "1.3", accepted when connecting to
+	// Splunk. Defaults to 1.2 when empty.
+	MinTLSVersion string
+
+	// ValidateJWT makes the operator check at startup that the Splunk authentication token is a
+	// well-formed JWT. Leave it unset for deployments that authenticate with opaque tokens.
+	ValidateJWT bool
+
+	// StrictDecoding rejects ACS token responses with unknown fields or trailing data,
+	// so API changes surface as errors. Responses are decoded leniently by default.
+	StrictDecoding bool
+
+	// RateLimit is the number of requests per second sent to the Splunk instance, with bursts
+	// of up to RateLimitBurst requests. MaxConcurrentRequests caps the requests in flight.
+	// Zero disables either limit.
+	RateLimit             float64
+	RateLimitBurst        int
+	MaxConcurrentRequests int
+	// InstanceLimits replaces RateLimit, RateLimitBurst and MaxConcurrentRequests for individual
+	// Splunk instances, keyed by instance name, so each instance is throttled according to its own
+	// capacity. Instances without an entry use the limits above.
+	InstanceLimits map[string]RequestLimits
+
+	// RetryableErrorCodes lists the codes of ACS error responses that indicate a transient
+	// condition. Requests failing with one of them are retried up to ErrorCodeRetries times,
+	// even with a 400-class status. No error codes are retried by default.
+	RetryableErrorCodes []string
+	ErrorCodeRetries    int
+
+	// MaintenanceErrorCodes lists the codes of ACS error responses returned while the Splunk
+	// instance is under maintenance. See General.MaintenanceWindows.
+	MaintenanceErrorCodes []string
+
+	// DeleteConsistencyWindow is how long after creating a HEC token a 404 response to deleting it
+	// is not trusted, since Splunk may not find a token created moments ago. The token is checked
+	// every DeleteConsistencyInterval (1 second when zero) until it shows up and is deleted again,
+	// or the window passes. Zero trusts every 404.
+	DeleteConsistencyWindow   time.Duration
+	DeleteConsistencyInterval time.Duration
+
+	// MaxErrorBodySize limits how many bytes of an ACS error response are read.
+	// Defaults to 64KiB when zero.
+	MaxErrorBodySize int64
+
+	// EnterpriseURL, if set, is the management URL of a Splunk Enterprise instance
+	// (e.g. https://splunk.example.com:8089). Tokens are then managed through its REST API
+	// instead of ACS, which Splunk Enterprise does not provide, and General.SplunkInstance is
+	// not required. MetadataFields and ValidateIndexes only apply to ACS.
+	EnterpriseURL string
+}
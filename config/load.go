@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Load reads the operator configuration from path.
+// If path is a directory, every *.toml file in it is decoded in lexical order
+// into the same configuration, so later files layer over earlier ones:
+//   - a scalar value set in a later file overrides the earlier value
+//   - a list set in a later file replaces the earlier list
+//   - a table (map) set in a later file is merged key by key,
+//     with keys from the later file taking precedence
+//
+// Anything a later file does not set is left as configured by earlier files.
+func Load(path string) (Splunk, error) {
+	var splunkConfig Splunk
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return splunkConfig, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.toml"))
+		if err != nil {
+			return splunkConfig, err
+		}
+		if len(files) == 0 {
+			return splunkConfig, fmt.Errorf("no config files found in %s", path)
+		}
+		sort.Strings(files)
+	}
+
+	for _, file := range files {
+		if _, err := toml.DecodeFile(file, &splunkConfig); err != nil {
+			return splunkConfig, fmt.Errorf("error parsing %s: %w", file, err)
+		}
+	}
+	return splunkConfig, nil
+}
+
+// Overrides are configuration values given on the command line.
+// They take precedence over the values loaded from the config file.
+type Overrides struct {
+	SplunkInstance string
+}
+
+// Apply sets the overrides that are not empty in splunkConfig.
+func (o Overrides) Apply(splunkConfig *Splunk) {
+	if o.SplunkInstance != "" {
+		splunkConfig.SplunkInstance = o.SplunkInstance
+	}
+}
+
+// LoadCheck returns a health check reporting the result of Load and Validate, so an invalid
+// configuration can be told apart from other failures through the probe endpoints.
+func LoadCheck(loadErr error) func(*http.Request) error {
+	return func(*http.Request) error {
+		if loadErr != nil {
+			return fmt.Errorf("config invalid: %w", loadErr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("loads a single file", func(t *testing.T) {
+		dir := t.TempDir()
+		file := writeConfig(t, dir, "splunktoken.toml", `
+[General]
+SplunkInstance = "base"
+TokenMaxAge = "24h"
+`)
+
+		got, err := Load(file)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if got.SplunkInstance != "base" {
+			t.Errorf("expected SplunkInstance base but got %s", got.SplunkInstance)
+		}
+		if got.TokenMaxAge != 24*time.Hour {
+			t.Errorf("expected TokenMaxAge 24h but got %s", got.TokenMaxAge)
+		}
+	})
+
+	t.Run("merges overlapping files in a directory in lexical order", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfig(t, dir, "10-base.toml", `
+[General]
+SplunkInstance = "base"
+TokenMaxAge = "24h"
+SecretLabels = { team = "sre", tier = "base" }
+
+[Classic]
+DefaultIndex = "base_index"
+AllowedIndexes = ["one", "two", "three"]
+`)
+		writeConfig(t, dir, "20-overlay.toml", `
+[General]
+SplunkInstance = "overlay"
+SecretLabels = { tier = "overlay" }
+
+[Classic]
+AllowedIndexes = ["four"]
+`)
+		writeConfig(t, dir, "ignored.yaml", `not: toml`)
+
+		got, err := Load(dir)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if got.SplunkInstance != "overlay" {
+			t.Errorf("expected later file to override SplunkInstance, got %s", got.SplunkInstance)
+		}
+		if got.TokenMaxAge != 24*time.Hour {
+			t.Errorf("expected TokenMaxAge from earlier file to be kept, got %s", got.TokenMaxAge)
+		}
+		if got.Classic.DefaultIndex != "base_index" {
+			t.Errorf("expected DefaultIndex from earlier file to be kept, got %s", got.Classic.DefaultIndex)
+		}
+		if want := []string{"four"}; !slices.Equal(got.Classic.AllowedIndexes, want) {
+			t.Errorf("expected later file to replace AllowedIndexes with %v, got %v", want, got.Classic.AllowedIndexes)
+		}
+		if want := map[string]string{"team": "sre", "tier": "overlay"}; !maps.Equal(got.SecretLabels, want) {
+			t.Errorf("expected merged SecretLabels %v, got %v", want, got.SecretLabels)
+		}
+	})
+
+	t.Run("loads request limits of individual instances", func(t *testing.T) {
+		file := writeConfig(t, t.TempDir(), "splunktoken.toml", `
+[General]
+SplunkInstance = "base"
+SplunkInstances = ["small"]
+
+[ACS]
+RateLimit = 10.0
+RateLimitBurst = 20
+MaxConcurrentRequests = 8
+
+[ACS.InstanceLimits.small]
+RateLimit = 1.0
+MaxConcurrentRequests = 2
+`)
+
+		got, err := Load(file)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if want := (RequestLimits{RateLimit: 10, RateLimitBurst: 20, MaxConcurrentRequests: 8}); got.ACS.LimitsFor("base") != want {
+			t.Errorf("expected instance without an entry to use limits %v, got %v", want, got.ACS.LimitsFor("base"))
+		}
+		if want := (RequestLimits{RateLimit: 1, MaxConcurrentRequests: 2}); got.ACS.LimitsFor("small") != want {
+			t.Errorf("expected instance limits %v, got %v", want, got.ACS.LimitsFor("small"))
+		}
+	})
+
+	t.Run("returns error for empty directory", func(t *testing.T) {
+		if _, err := Load(t.TempDir()); err == nil {
+			t.Error("expected error but did not get one")
+		}
+	})
+
+	t.Run("returns error naming the malformed file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfig(t, dir, "10-base.toml", `[General]`)
+		bad := writeConfig(t, dir, "20-bad.toml", `[General`)
+
+		_, err := Load(dir)
+		if err == nil {
+			t.Fatal("expected error but did not get one")
+		}
+		if !strings.Contains(err.Error(), bad) {
+			t.Errorf("expected error to name %s, got %s", bad, err)
+		}
+	})
+}
+
+func TestOverridesApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides Overrides
+		want      string
+	}{
+		{name: "keeps the config file value when not set", overrides: Overrides{}, want: "from-file"},
+		{name: "flag value takes precedence", overrides: Overrides{SplunkInstance: "from-flag"}, want: "from-flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := writeConfig(t, dir, "splunktoken.toml", `
+[General]
+SplunkInstance = "from-file"
+TokenMaxAge = "24h"
+`)
+			splunkConfig, err := Load(file)
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+
+			tt.overrides.Apply(&splunkConfig)
+			if splunkConfig.SplunkInstance != tt.want {
+				t.Errorf("expected SplunkInstance %s but got %s", tt.want, splunkConfig.SplunkInstance)
+			}
+			if splunkConfig.TokenMaxAge != 24*time.Hour {
+				t.Errorf("expected other values to be kept but got TokenMaxAge %s", splunkConfig.TokenMaxAge)
+			}
+		})
+	}
+}
+
+func TestLoadCheck(t *testing.T) {
+	t.Run("passes for a valid config", func(t *testing.T) {
+		file := writeConfig(t, t.TempDir(), "splunktoken.toml", `[General]`)
+		_, err := Load(file)
+		if err := LoadCheck(err)(nil); err != nil {
+			t.Errorf("got unexpected error: %s", err)
+		}
+	})
+
+	t.Run("reports a malformed config", func(t *testing.T) {
+		file := writeConfig(t, t.TempDir(), "splunktoken.toml", `[General`)
+		_, err := Load(file)
+		checkErr := LoadCheck(err)(nil)
+		if checkErr == nil {
+			t.Fatal("expected error but did not get one")
+		}
+		if !strings.HasPrefix(checkErr.Error(), "config invalid") || !strings.Contains(checkErr.Error(), file) {
+			t.Errorf("expected config invalid error naming %s, got %s", file, checkErr)
+		}
+	})
+
+	t.Run("reports a setting that fails validation", func(t *testing.T) {
+		file := writeConfig(t, t.TempDir(), "splunktoken.toml", `
+[General]
+SplunkInstance = "osdsecuritylogs"
+RotationStrategy = "secrets"
+`)
+		splunkConfig, err := Load(file)
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		checkErr := LoadCheck(splunkConfig.Validate())(nil)
+		if checkErr == nil || !strings.Contains(checkErr.Error(), "RotationStrategy") {
+			t.Errorf("expected config invalid error for RotationStrategy, got %v", checkErr)
+		}
+	})
+}
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("error writing config file: %s", err)
+	}
+	return path
+}
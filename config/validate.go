@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Validate reports the settings of splunkConfig that the operator cannot run with,
+// joining an error for each invalid setting.
+func (s Splunk) Validate() error {
+	var errs []error
+	if s.SplunkInstance == "" && s.ACS.EnterpriseURL == "" {
+		errs = append(errs, errors.New("General.SplunkInstance must be set"))
+	}
+	if s.ACS.EnterpriseURL != "" && s.EnterpriseHECURL == "" {
+		errs = append(errs, errors.New("General.EnterpriseHECURL must be set with ACS.EnterpriseURL"))
+	}
+	if s.EnterpriseHECURL != "" && s.ACS.EnterpriseURL == "" {
+		errs = append(errs, errors.New("General.EnterpriseHECURL requires ACS.EnterpriseURL"))
+	}
+	if s.TokenMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("General.TokenMaxAge must not be negative, got %s", s.TokenMaxAge))
+	}
+	if s.TokenQuotaWarningRatio < 0 || s.TokenQuotaWarningRatio > 1 {
+		errs = append(errs, fmt.Errorf("General.TokenQuotaWarningRatio must be between 0 and 1, got %v", s.TokenQuotaWarningRatio))
+	}
+	for _, window := range s.MaintenanceWindows {
+		if !window.End.After(window.Start) {
+			errs = append(errs, fmt.Errorf("General.MaintenanceWindows must end after they start, got %s to %s",
+				window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339)))
+		}
+	}
+	if !oneOf(s.RotationStrategy, RotationStrategyObject, RotationStrategySecret) {
+		errs = append(errs, fmt.Errorf("General.RotationStrategy must be %q or %q, got %q",
+			RotationStrategyObject, RotationStrategySecret, s.RotationStrategy))
+	}
+	if !oneOf(s.SecretLifecycle, SecretLifecycleOwnerReference, SecretLifecycleFinalizer) {
+		errs = append(errs, fmt.Errorf("General.SecretLifecycle must be %q or %q, got %q",
+			SecretLifecycleOwnerReference, SecretLifecycleFinalizer, s.SecretLifecycle))
+	}
+	if !oneOf(s.StaleSecretPolicy, StaleSecretPolicyReissue, StaleSecretPolicyWait) {
+		errs = append(errs, fmt.Errorf("General.StaleSecretPolicy must be %q or %q, got %q",
+			StaleSecretPolicyReissue, StaleSecretPolicyWait, s.StaleSecretPolicy))
+	}
+	if !oneOf(s.DuplicateTokenPolicy, DuplicateTokenPolicyIgnore, DuplicateTokenPolicyAdopt, DuplicateTokenPolicyError) {
+		errs = append(errs, fmt.Errorf("General.DuplicateTokenPolicy must be %q, %q or %q, got %q",
+			DuplicateTokenPolicyIgnore, DuplicateTokenPolicyAdopt, DuplicateTokenPolicyError, s.DuplicateTokenPolicy))
+	}
+	if !oneOf(s.ACS.UpdateMethod, http.MethodPut, http.MethodPatch) {
+		errs = append(errs, fmt.Errorf("ACS.UpdateMethod must be %s or %s, got %q", http.MethodPut, http.MethodPatch, s.ACS.UpdateMethod))
+	}
+	if _, found := TLSVersions[s.ACS.MinTLSVersion]; s.ACS.MinTLSVersion != "" && !found {
+		errs = append(errs, fmt.Errorf("ACS.MinTLSVersion must be 1.2 or 1.3, got %q", s.ACS.MinTLSVersion))
+	}
+	for instance := range s.ACS.InstanceLimits {
+		if instance != s.SplunkInstance && !slices.Contains(s.SplunkInstances, instance) {
+			errs = append(errs, fmt.Errorf("ACS.InstanceLimits has limits for unknown Splunk instance %q", instance))
+		}
+	}
+	for _, deployment := range []struct {
+		name string
+		Deployment
+	}{{"Classic", s.Classic}, {"HCP", s.HCP}} {
+		for _, index := range append([]string{deployment.DefaultIndex}, deployment.AllowedIndexes...) {
+			if index != strings.TrimSpace(index) {
+				errs = append(errs, fmt.Errorf("%s index %q has surrounding whitespace", deployment.name, index))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// oneOf reports whether value is empty, which selects the default, or one of the allowed values.
+func oneOf(value string, allowed ...string) bool {
+	return value == "" || slices.Contains(allowed, value)
+}
+
+// WriteSummary writes the effective settings that matter most when reviewing a config change.
+func (s Splunk) WriteSummary(w io.Writer) error {
+	api := "ACS"
+	if s.ACS.EnterpriseURL != "" {
+		api = "Splunk Enterprise " + s.ACS.EnterpriseURL
+		if s.EnterpriseHECURL != "" {
+			api += ", HEC " + s.EnterpriseHECURL
+		}
+	}
+	rotation := "disabled"
+	if s.TokenMaxAge > 0 {
+		strategy := s.RotationStrategy
+		if strategy == "" {
+			strategy = RotationStrategyObject
+		}
+		rotation = fmt.Sprintf("after %s, %s strategy", s.TokenMaxAge, strategy)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Splunk instance:\t%s\n", s.SplunkInstance)
+	if len(s.SplunkInstances) > 0 {
+		fmt.Fprintf(tw, "Other instances:\t%s\n", strings.Join(s.SplunkInstances, ", "))
+	}
+	fmt.Fprintf(tw, "Token API:\t%s\n", api)
+	fmt.Fprintf(tw, "Token rotation:\t%s\n", rotation)
+	fmt.Fprintf(tw, "Classic indexes:\t%s\n", indexSummary(s.Classic))
+	fmt.Fprintf(tw, "HCP indexes:\t%s\n", indexSummary(s.HCP))
+	if s.FallbackIndex != "" {
+		fmt.Fprintf(tw, "Fallback index:\t%s\n", s.FallbackIndex)
+	}
+	if s.Paused {
+		fmt.Fprintf(tw, "Reconciliation:\tpaused\n")
+	}
+	return tw.Flush()
+}
+
+// indexSummary describes the default and allowed indexes of a deployment.
+func indexSummary(d Deployment) string {
+	defaultIndex := d.DefaultIndex
+	if defaultIndex == "" {
+		defaultIndex = "none"
+	}
+	if len(d.AllowedIndexes) == 0 {
+		return fmt.Sprintf("default %s", defaultIndex)
+	}
+	return fmt.Sprintf("default %s, allowed %s", defaultIndex, strings.Join(d.AllowedIndexes, ", "))
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr []string
+	}{
+		{
+			name: "accepts valid config",
+			config: `
+[General]
+SplunkInstance = "osdsecuritylogs"
+TokenMaxAge = "24h"
+RotationStrategy = "secret"
+DuplicateTokenPolicy = "adopt"
+SecretLifecycle = "finalizer"
+StaleSecretPolicy = "wait"
+MaintenanceWindows = [{ Start = 2025-06-01T02:00:00Z, End = 2025-06-01T04:00:00Z }]
+
+[Classic]
+DefaultIndex = "development"
+AllowedIndexes = ["audit"]
+
+[ACS]
+UpdateMethod = "PATCH"
+MinTLSVersion = "1.3"
+
+[ACS.InstanceLimits.osdsecuritylogs]
+MaxConcurrentRequests = 2
+`,
+		},
+		{
+			name: "accepts Splunk Enterprise without instance",
+			config: `
+[General]
+EnterpriseHECURL = "https://splunk.example.com:8088"
+
+[ACS]
+EnterpriseURL = "https://splunk.example.com:8089"
+`,
+		},
+		{
+			name: "requires HEC URL with Splunk Enterprise",
+			config: `
+[General]
+SplunkInstance = "osdsecuritylogs"
+
+[ACS]
+EnterpriseURL = "https://splunk.example.com:8089"
+`,
+			wantErr: []string{"EnterpriseHECURL must be set with ACS.EnterpriseURL"},
+		},
+		{
+			name: "accepts defaults for optional settings",
+			config: `
+[General]
+SplunkInstance = "osdsecuritylogs"
+`,
+		},
+		{
+			name: "reports every invalid setting",
+			config: `
+[General]
+TokenMaxAge = "-1h"
+RotationStrategy = "secrets"
+DuplicateTokenPolicy = "skip"
+SecretLifecycle = "gc"
+StaleSecretPolicy = "keep"
+TokenQuotaWarningRatio = 90.0
+EnterpriseHECURL = "https://splunk.example.com:8088"
+MaintenanceWindows = [{ Start = 2025-06-01T04:00:00Z, End = 2025-06-01T02:00:00Z }]
+
+[HCP]
+AllowedIndexes = ["audit "]
+
+[ACS]
+UpdateMethod = "POST"
+MinTLSVersion = "1.1"
+
+[ACS.InstanceLimits.unknown]
+RateLimit = 1.0
+`,
+			wantErr: []string{
+				"SplunkInstance must be set",
+				"EnterpriseHECURL requires ACS.EnterpriseURL",
+				"TokenMaxAge must not be negative",
+				"TokenQuotaWarningRatio must be between 0 and 1, got 90",
+				"MaintenanceWindows must end after they start, got 2025-06-01T04:00:00Z to 2025-06-01T02:00:00Z",
+				`RotationStrategy must be "object" or "secret", got "secrets"`,
+				`SecretLifecycle must be "owner-reference" or "finalizer", got "gc"`,
+				`StaleSecretPolicy must be "reissue" or "wait", got "keep"`,
+				`DuplicateTokenPolicy must be "ignore", "adopt" or "error", got "skip"`,
+				`HCP index "audit " has surrounding whitespace`,
+				`UpdateMethod must be PUT or PATCH, got "POST"`,
+				`MinTLSVersion must be 1.2 or 1.3, got "1.1"`,
+				`InstanceLimits has limits for unknown Splunk instance "unknown"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkConfig, err := Load(writeConfig(t, t.TempDir(), "splunktoken.toml", tt.config))
+			if err != nil {
+				t.Fatalf("got unexpected error loading config: %s", err)
+			}
+			err = splunkConfig.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("expected valid config but got %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q but got %s", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	splunkConfig := Splunk{
+		General: General{
+			SplunkInstance:   "osdsecuritylogs",
+			TokenMaxAge:      24 * time.Hour,
+			RotationStrategy: RotationStrategySecret,
+			Paused:           true,
+			EnterpriseHECURL: "https://splunk.example.com:8088",
+		},
+		Classic: Deployment{DefaultIndex: "development", AllowedIndexes: []string{"audit", "infra"}},
+		ACS:     ACS{EnterpriseURL: "https://splunk.example.com:8089"},
+	}
+
+	var summary strings.Builder
+	if err := splunkConfig.WriteSummary(&summary); err != nil {
+		t.Fatalf("got unexpected error: %s", err)
+	}
+	for _, want := range []string{
+		"osdsecuritylogs",
+		"Splunk Enterprise https://splunk.example.com:8089, HEC https://splunk.example.com:8088",
+		"after 24h0m0s, secret strategy",
+		"default development, allowed audit, infra",
+		"HCP indexes:      default none",
+		"paused",
+	} {
+		if !strings.Contains(summary.String(), want) {
+			t.Errorf("expected summary to contain %q but got:\n%s", want, summary.String())
+		}
+	}
+}
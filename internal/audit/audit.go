@@ -0,0 +1,83 @@
+// Package audit writes a record of every HEC token creation, rotation, and deletion
+// for compliance. Audit records are kept separate from the operator's operational logs
+// and never contain token values.
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// An Action is a change to a HEC token recorded in the audit log.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionRotate Action = "rotate"
+	ActionDelete Action = "delete"
+)
+
+// StdoutSink is the sink path that writes audit records to standard output.
+const StdoutSink = "-"
+
+// A Record is a single audit log entry, written as one line of JSON.
+// It identifies the token by name only and must never contain its value.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Action    Action    `json:"action"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	ClusterID string    `json:"clusterID"`
+}
+
+// A Logger writes audit records to a sink.
+// A nil Logger discards everything it is given.
+type Logger struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+// NewLogger creates a Logger writing to out.
+func NewLogger(out io.Writer) *Logger {
+	return &Logger{out: out, now: time.Now}
+}
+
+// Open creates a Logger for the sink at path: standard output for StdoutSink,
+// otherwise a file that records are appended to.
+func Open(path string) (*Logger, error) {
+	if path == StdoutSink {
+		return NewLogger(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 -- path is operator config
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(file), nil
+}
+
+// Record writes an audit record for the action on the SplunkToken namespace/name,
+// whose HEC token is named clusterID.
+func (l *Logger) Record(action Action, namespace, name, clusterID string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record := Record{
+		Time:      l.now().UTC(),
+		Action:    action,
+		Namespace: namespace,
+		Name:      name,
+		ClusterID: clusterID,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = l.out.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	t.Run("writes one JSON record per line", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := NewLogger(&out)
+		logger.now = func() time.Time { return time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC) }
+
+		if err := logger.Record(ActionRotate, "uhc-production-abc", "cluster", "<internal-cluster-id>"); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if err := logger.Record(ActionDelete, "uhc-production-abc", "cluster", "<internal-cluster-id>"); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+
+		lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 records but got %d: %s", len(lines), out.String())
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(lines[0], &fields); err != nil {
+			t.Fatalf("error decoding record: %s", err)
+		}
+		wantKeys := []string{"action", "clusterID", "name", "namespace", "time"}
+		if keys := slices.Sorted(maps.Keys(fields)); !slices.Equal(keys, wantKeys) {
+			t.Errorf("expected record keys %v but got %v", wantKeys, keys)
+		}
+		want := map[string]any{
+			"time":      "2025-01-01T00:00:00Z",
+			"action":    "rotate",
+			"namespace": "uhc-production-abc",
+			"name":      "cluster",
+			"clusterID": "<internal-cluster-id>",
+		}
+		if !maps.Equal(fields, want) {
+			t.Errorf("expected record %v but got %v", want, fields)
+		}
+	})
+
+	t.Run("nil logger discards records", func(t *testing.T) {
+		var logger *Logger
+		if err := logger.Record(ActionCreate, "namespace", "cluster", "id"); err != nil {
+			t.Errorf("got unexpected error: %s", err)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+// startTestEnv starts an API server with the SplunkToken CRD installed and returns a client for it.
+// The test is skipped when the envtest binaries are not available, which `make test` sets up.
+func startTestEnv(t *testing.T, scheme *runtime.Scheme) client.Client {
+	t.Helper()
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, run with make test to use envtest")
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("error starting test environment: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("error stopping test environment: %s", err)
+		}
+	})
+
+	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	return k8sClient
+}
+
+// stubSplunk serves the Splunk Enterprise HEC token API from memory.
+type stubSplunk struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (s *stubSplunk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := filepath.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.tokens[r.PostForm.Get("name")] = testTokenValue
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		value, found := s.tokens[name]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"entry":[{"name":"http://%s","content":{"token":"%s"}}]}`, name, value)
+	case http.MethodDelete:
+		delete(s.tokens, name)
+	}
+}
+
+func (s *stubSplunk) hasToken(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, found := s.tokens[name]
+	return found
+}
+
+func TestReconcileIntegration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	k8sClient := startTestEnv(t, scheme)
+
+	splunk := &stubSplunk{tokens: map[string]string{}}
+	splunkServer := httptest.NewServer(splunk)
+	defer splunkServer.Close()
+	splunkClient, err := splunkapi.NewClient("mock_splunk", "foo", splunkapi.WithEnterpriseAPI(splunkServer.URL))
+	if err != nil {
+		t.Fatalf("error creating Splunk client: %s", err)
+	}
+
+	reconciler := SplunkTokenReconciler{
+		Client:       k8sClient,
+		Scheme:       scheme,
+		Recorder:     record.NewFakeRecorder(10),
+		SplunkApi:    splunkClient,
+		SplunkConfig: config.General{TokenMaxAge: time.Hour},
+	}
+
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: request.Namespace}}
+	if err := k8sClient.Create(t.Context(), &namespace); err != nil {
+		t.Fatalf("error creating namespace: %s", err)
+	}
+
+	t.Run("rejects SplunkToken without a token name", func(t *testing.T) {
+		invalidToken := unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": stv1alpha1.GroupVersion.String(),
+			"kind":       "SplunkToken",
+			"metadata":   map[string]any{"namespace": request.Namespace, "name": "invalid"},
+			"spec":       map[string]any{"defaultIndex": "main"},
+		}}
+		if err := k8sClient.Create(t.Context(), &invalidToken); !kerrors.IsInvalid(err) {
+			t.Errorf("expected SplunkToken without name to be rejected but got %v", err)
+		}
+	})
+
+	splunkToken := stv1alpha1.SplunkToken{
+		ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace, Name: request.Name},
+		Spec:       stv1alpha1.SplunkTokenSpec{Name: "integration-cluster-id"},
+	}
+	if err := k8sClient.Create(t.Context(), &splunkToken); err != nil {
+		t.Fatalf("error creating SplunkToken: %s", err)
+	}
+
+	t.Run("issues HEC token and stores it in a Secret", func(t *testing.T) {
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !splunk.hasToken(splunkToken.Spec.Name) {
+			t.Error("expected HEC token to be created in Splunk")
+		}
+		tokenSecret := getTokenSecret(t, k8sClient)
+		if value, _ := tokenValueFromSecret(&tokenSecret); value != testTokenValue {
+			t.Errorf("expected Secret to contain token value %s but got %s", testTokenValue, value)
+		}
+		if len(tokenSecret.OwnerReferences) != 1 || tokenSecret.OwnerReferences[0].Name != splunkToken.Name {
+			t.Errorf("expected Secret to be owned by the SplunkToken but got %v", tokenSecret.OwnerReferences)
+		}
+		if err := k8sClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if splunkToken.Status.TokenIssuedAt == nil {
+			t.Error("expected token issue time to be stored in status")
+		}
+	})
+
+	t.Run("deletes HEC token when SplunkToken is deleted", func(t *testing.T) {
+		if err := k8sClient.Delete(t.Context(), &splunkToken); err != nil {
+			t.Fatalf("error deleting SplunkToken: %s", err)
+		}
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if splunk.hasToken(splunkToken.Spec.Name) {
+			t.Error("expected HEC token to be deleted from Splunk")
+		}
+		if err := k8sClient.Get(t.Context(), request.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+			t.Errorf("expected finalized SplunkToken to be removed but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package controller
+
+import "sync"
+
+// tokenLocks serializes Splunk operations on the same HEC token. controller-runtime never
+// reconciles one object concurrently, but SplunkTokens in different namespaces, or other
+// controllers in the operator, may act on the same token name at the same time.
+var tokenLocks keyedMutex
+
+// A keyedMutex is a set of mutexes keyed by name. Operations on the same key are
+// serialized while different keys proceed concurrently. The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// Lock locks the mutex for key and returns the function that unlocks it.
+// The mutex is discarded once no goroutine holds or waits for it.
+func (k *keyedMutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*keyedLock{}
+	}
+	lock, found := k.locks[key]
+	if !found {
+		lock = &keyedLock{}
+		k.locks[key] = lock
+	}
+	lock.waiters++
+	k.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		k.mu.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
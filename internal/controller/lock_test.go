@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	t.Run("serializes the same key", func(t *testing.T) {
+		var locks keyedMutex
+		var inFlight, maxInFlight atomic.Int32
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock := locks.Lock("token")
+				defer unlock()
+				current := inFlight.Add(1)
+				if current > maxInFlight.Load() {
+					maxInFlight.Store(current)
+				}
+				time.Sleep(time.Millisecond)
+				inFlight.Add(-1)
+			}()
+		}
+		wg.Wait()
+		if got := maxInFlight.Load(); got != 1 {
+			t.Errorf("expected at most 1 holder of the lock but got %d", got)
+		}
+		if len(locks.locks) != 0 {
+			t.Errorf("expected unused locks to be discarded but got %d", len(locks.locks))
+		}
+	})
+
+	t.Run("does not block other keys", func(t *testing.T) {
+		var locks keyedMutex
+		unlock := locks.Lock("first")
+		defer unlock()
+
+		done := make(chan struct{})
+		go func() {
+			locks.Lock("second")()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("expected lock on a different key not to block")
+		}
+	})
+}
+
+func TestReconcileConcurrentTokenName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	// two SplunkTokens in different namespaces manage the same HEC token
+	first := testSplunkToken()
+	second := testSplunkToken()
+	second.Namespace = "other-namespace"
+
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+		WithRuntimeObjects(&first, &second).
+		Build()
+
+	var inFlight, maxInFlight atomic.Int32
+	slowCreate := func() (*splunkapi.HECToken, error) {
+		current := inFlight.Add(1)
+		if current > maxInFlight.Load() {
+			maxInFlight.Store(current)
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		return createSuccess()
+	}
+
+	var wg sync.WaitGroup
+	for _, token := range []stv1alpha1.SplunkToken{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunkClient{create: slowCreate, delete: deleteErrorIfCalled},
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+			}
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: token.Namespace, Name: token.Name}}
+			if _, err := reconciler.Reconcile(t.Context(), req); err != nil {
+				t.Errorf("unexpected error during reconcile: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got != 1 {
+		t.Errorf("expected CreateToken calls for the same token name to be serialized but %d ran concurrently", got)
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+// TokenMetadata is the non-secret description of a HEC token published to the metadata ConfigMap.
+// It must never contain the token value.
+type TokenMetadata struct {
+	Name           string     `json:"name"`
+	DefaultIndex   string     `json:"defaultIndex,omitempty"`
+	AllowedIndexes []string   `json:"allowedIndexes,omitempty"`
+	RotatesAt      *time.Time `json:"rotatesAt,omitempty"`
+}
+
+// publishTokenMetadata records the SplunkToken's metadata in the namespace's metadata ConfigMap,
+// keyed by the SplunkToken object name. It does nothing if no ConfigMap name is configured.
+func (r *SplunkTokenReconciler) publishTokenMetadata(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) error {
+	if r.SplunkConfig.MetadataConfigMapName == "" {
+		return nil
+	}
+	metadata := TokenMetadata{
+		Name:           tokenObject.Spec.Name,
+		DefaultIndex:   tokenObject.Spec.DefaultIndex,
+		AllowedIndexes: tokenObject.Spec.AllowedIndexes,
+	}
+	if maxAge, _ := tokenMaxAge(r.SplunkConfig, tokenObject); maxAge > 0 {
+		rotatesAt := rotationDeadline(r.SplunkConfig, tokenObject).UTC()
+		metadata.RotatesAt = &rotatesAt
+	}
+	value, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	configMap := corev1.ConfigMap{}
+	configMap.Name = r.SplunkConfig.MetadataConfigMapName
+	configMap.Namespace = tokenObject.Namespace
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, &configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[tokenObject.Name] = string(value)
+		return controllerutil.SetOwnerReference(tokenObject, &configMap, r.Scheme)
+	})
+	return err
+}
+
+// removeTokenMetadata removes a deleted SplunkToken's entry from the metadata ConfigMap.
+func (r *SplunkTokenReconciler) removeTokenMetadata(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) error {
+	if r.SplunkConfig.MetadataConfigMapName == "" {
+		return nil
+	}
+	configMap := corev1.ConfigMap{}
+	configMap.Name = r.SplunkConfig.MetadataConfigMapName
+	configMap.Namespace = tokenObject.Namespace
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&configMap), &configMap); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, found := configMap.Data[tokenObject.Name]; !found {
+		return nil
+	}
+	delete(configMap.Data, tokenObject.Name)
+	return r.Update(ctx, &configMap)
+}
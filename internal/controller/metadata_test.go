@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+const testMetadataConfigMap = "splunk-hec-token-metadata"
+
+func TestReconcileTokenMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	configMapKey := types.NamespacedName{Namespace: request.Namespace, Name: testMetadataConfigMap}
+
+	t.Run("publishes token metadata without the token value", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.DefaultIndex = "main"
+		splunkToken.Spec.AllowedIndexes = []string{"main", "audit"}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{
+				TokenMaxAge:           time.Hour,
+				MetadataConfigMapName: testMetadataConfigMap,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+
+		var configMap corev1.ConfigMap
+		if err := fakeClient.Get(t.Context(), configMapKey, &configMap); err != nil {
+			t.Fatalf("error getting metadata ConfigMap: %s", err)
+		}
+		for key, value := range configMap.Data {
+			if strings.Contains(value, testTokenValue) {
+				t.Errorf("metadata ConfigMap key %s contains the HEC token value", key)
+			}
+		}
+		var metadata TokenMetadata
+		if err := json.Unmarshal([]byte(configMap.Data[request.Name]), &metadata); err != nil {
+			t.Fatalf("error decoding token metadata: %s", err)
+		}
+		if metadata.Name != splunkToken.Spec.Name {
+			t.Errorf("expected token name %s but got %s", splunkToken.Spec.Name, metadata.Name)
+		}
+		if metadata.DefaultIndex != "main" {
+			t.Errorf("expected default index main but got %s", metadata.DefaultIndex)
+		}
+		if !slices.Equal(metadata.AllowedIndexes, splunkToken.Spec.AllowedIndexes) {
+			t.Errorf("expected allowed indexes %v but got %v", splunkToken.Spec.AllowedIndexes, metadata.AllowedIndexes)
+		}
+		// the stored creation timestamp has second precision
+		wantRotation := splunkToken.CreationTimestamp.Add(time.Hour).Truncate(time.Second)
+		if metadata.RotatesAt == nil || !metadata.RotatesAt.Equal(wantRotation) {
+			t.Errorf("expected rotation time %s but got %v", wantRotation, metadata.RotatesAt)
+		}
+		if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].Name != splunkToken.Name {
+			t.Errorf("expected ConfigMap to be owned by the SplunkToken but got %v", configMap.OwnerReferences)
+		}
+	})
+
+	t.Run("removes metadata when token is deleted", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		configMap := corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace, Name: testMetadataConfigMap},
+			Data: map[string]string{
+				request.Name: `{"name":"<internal-cluster-id>"}`,
+				"other":      `{"name":"other"}`,
+			},
+		}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken, &configMap).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess},
+			SplunkConfig: config.General{
+				TokenMaxAge:           time.Hour,
+				MetadataConfigMapName: testMetadataConfigMap,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+
+		if err := fakeClient.Get(t.Context(), configMapKey, &configMap); err != nil {
+			t.Fatalf("error getting metadata ConfigMap: %s", err)
+		}
+		if _, found := configMap.Data[request.Name]; found {
+			t.Error("expected deleted token to be removed from metadata ConfigMap")
+		}
+		if _, found := configMap.Data["other"]; !found {
+			t.Error("expected other tokens to remain in metadata ConfigMap")
+		}
+	})
+
+	t.Run("does not create ConfigMap when not configured", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     record.NewFakeRecorder(1),
+			SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		var configMap corev1.ConfigMap
+		if err := fakeClient.Get(t.Context(), configMapKey, &configMap); !kerrors.IsNotFound(err) {
+			t.Errorf("expected no metadata ConfigMap but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+// BuildOutputsConf returns the outputs.conf content stored in the token Secret, which points the
+// forwarder at the HEC endpoint of the token's Splunk instance with the token's value.
+// The token's SplunkInstance selects the instance, defaulting to the configured SplunkInstance.
+// A sourcetype line is added with the token's Sourcetype, or else the configured OutputSourcetype.
+func BuildOutputsConf(token splunkapi.HECToken, cfg config.General) []byte {
+	stanza := cfg.OutputStanza
+	if stanza == "" {
+		stanza = config.OutputStanza
+	}
+	outputsConf := `[%s]
+httpEventCollectorToken = %s
+uri = %s`
+	data := fmt.Appendf([]byte{}, outputsConf, stanza, token.Value, collectorURI(token.Spec.SplunkInstance, cfg))
+	sourcetype := token.Spec.Sourcetype
+	if sourcetype == "" {
+		sourcetype = cfg.OutputSourcetype
+	}
+	if sourcetype == "" {
+		return data
+	}
+	return fmt.Appendf(data, "\nsourcetype = %s", sourcetype)
+}
+
+// BuildIndexRouting returns the index routing rules stored in the token Secret with SecretIndexRouting,
+// so a forwarder can route events to the token's indexes and sourcetype from the same Secret.
+// Allowed indexes are listed comma separated in the order of the spec.
+func BuildIndexRouting(spec stv1alpha1.SplunkTokenSpec) []byte {
+	indexRouting := `[%s]
+defaultIndex = %s
+allowedIndexes = %s
+sourcetype = %s`
+	return fmt.Appendf([]byte{}, indexRouting, config.IndexRoutingStanza,
+		spec.DefaultIndex, strings.Join(spec.AllowedIndexes, ","), spec.Sourcetype)
+}
+
+// setTargetStatus records the Splunk instance holding the SplunkToken's HEC token and the
+// collector URI of its Secret in the SplunkToken's status. It reports whether the status changed.
+func setTargetStatus(tokenObject *stv1alpha1.SplunkToken, cfg config.General) bool {
+	instance := tokenObject.Spec.SplunkInstance
+	if instance == "" {
+		instance = cfg.SplunkInstance
+	}
+	uri := collectorURI(instance, cfg)
+	if tokenObject.Status.SplunkInstance == instance && tokenObject.Status.CollectorURI == uri {
+		return false
+	}
+	tokenObject.Status.SplunkInstance = instance
+	tokenObject.Status.CollectorURI = uri
+	return true
+}
+
+// collectorURI returns the HEC endpoint of the Splunk Cloud instance, or of the configured
+// SplunkInstance when instance is empty. With Splunk Enterprise it is always EnterpriseHECURL.
+func collectorURI(instance string, cfg config.General) string {
+	if cfg.EnterpriseHECURL != "" {
+		return cfg.EnterpriseHECURL
+	}
+	if instance == "" {
+		instance = cfg.SplunkInstance
+	}
+	return fmt.Sprintf("https://http-inputs-%s.splunkcloud.com:443", instance)
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"testing"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+func TestBuildOutputsConf(t *testing.T) {
+	tests := []struct {
+		name  string
+		token splunkapi.HECToken
+		cfg   config.General
+		want  string
+	}{
+		{
+			name:  "uses default stanza and configured instance",
+			token: splunkapi.HECToken{Value: testTokenValue},
+			cfg:   config.General{SplunkInstance: "osdsecuritylogs"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443`,
+		},
+		{
+			name:  "uses configured stanza",
+			token: splunkapi.HECToken{Value: testTokenValue},
+			cfg:   config.General{SplunkInstance: "osdsecuritylogs", OutputStanza: "tcpout:security"},
+			want: `[tcpout:security]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443`,
+		},
+		{
+			name: "uses instance selected by token",
+			token: splunkapi.HECToken{
+				Spec:  stv1alpha1.SplunkTokenSpec{SplunkInstance: "osdsecuritylogs-eu"},
+				Value: testTokenValue,
+			},
+			cfg: config.General{SplunkInstance: "osdsecuritylogs"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs-eu.splunkcloud.com:443`,
+		},
+		{
+			name:  "adds configured sourcetype",
+			token: splunkapi.HECToken{Value: testTokenValue},
+			cfg:   config.General{SplunkInstance: "osdsecuritylogs", OutputSourcetype: "_json"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443
+sourcetype = _json`,
+		},
+		{
+			name: "prefers sourcetype of token",
+			token: splunkapi.HECToken{
+				Spec:  stv1alpha1.SplunkTokenSpec{Sourcetype: "osd:audit"},
+				Value: testTokenValue,
+			},
+			cfg: config.General{SplunkInstance: "osdsecuritylogs", OutputSourcetype: "_json"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443
+sourcetype = osd:audit`,
+		},
+		{
+			name: "adds sourcetype of token when not configured",
+			token: splunkapi.HECToken{
+				Spec:  stv1alpha1.SplunkTokenSpec{Sourcetype: "osd:audit"},
+				Value: testTokenValue,
+			},
+			cfg: config.General{SplunkInstance: "osdsecuritylogs"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443
+sourcetype = osd:audit`,
+		},
+		{
+			name: "uses Splunk Enterprise HEC endpoint",
+			token: splunkapi.HECToken{
+				Spec:  stv1alpha1.SplunkTokenSpec{SplunkInstance: "osdsecuritylogs-eu"},
+				Value: testTokenValue,
+			},
+			cfg: config.General{EnterpriseHECURL: "https://splunk.example.com:8088"},
+			want: `[httpout]
+httpEventCollectorToken = ` + testTokenValue + `
+uri = https://splunk.example.com:8088`,
+		},
+		{
+			name:  "keeps empty token value",
+			token: splunkapi.HECToken{},
+			cfg:   config.General{SplunkInstance: "osdsecuritylogs"},
+			want: `[httpout]
+httpEventCollectorToken = 
+uri = https://http-inputs-osdsecuritylogs.splunkcloud.com:443`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildOutputsConf(tt.token, tt.cfg)
+			if string(got) != tt.want {
+				t.Errorf("expected outputs.conf\n%s\nbut got\n%s", tt.want, got)
+			}
+			if match := tokenLinePattern.FindSubmatch(got); match == nil || string(match[1]) != tt.token.Value {
+				t.Errorf("expected token value %q to be readable from outputs.conf but got %q", tt.token.Value, match)
+			}
+		})
+	}
+}
+
+func TestBuildIndexRouting(t *testing.T) {
+	tests := []struct {
+		name string
+		spec stv1alpha1.SplunkTokenSpec
+		want string
+	}{
+		{
+			name: "lists indexes and sourcetype",
+			spec: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}, Sourcetype: "osd:audit"},
+			want: `[index_routing]
+defaultIndex = main
+allowedIndexes = audit,main
+sourcetype = osd:audit`,
+		},
+		{
+			name: "leaves unset fields empty",
+			spec: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			want: `[index_routing]
+defaultIndex = main
+allowedIndexes = 
+sourcetype = `,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildIndexRouting(tt.spec); string(got) != tt.want {
+				t.Errorf("expected index routing\n%s\nbut got\n%s", tt.want, got)
+			}
+		})
+	}
+}
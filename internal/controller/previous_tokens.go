@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+// tokenValueHash returns the hex encoded SHA-256 hash of a HEC token value, which identifies
+// a rotated token in the SplunkToken's status without storing its value.
+func tokenValueHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// recordPreviousToken adds the SplunkToken's current HEC token, identified by the hash of its
+// value, to its PreviousTokens ahead of a rotation, dropping the oldest entries beyond
+// PreviousTokensLimit. An undeleted entry left by an interrupted rotation of the same token
+// is reused rather than recorded twice.
+func (r *SplunkTokenReconciler) recordPreviousToken(tokenObject *stv1alpha1.SplunkToken, rotatedAt metav1.Time, valueHash string) {
+	previous := tokenObject.Status.PreviousTokens
+	if n := len(previous); n > 0 && previous[n-1].ValueHash == valueHash && !previous[n-1].Deleted {
+		previous[n-1].RotatedAt = rotatedAt
+		return
+	}
+	previous = append(previous, stv1alpha1.PreviousToken{
+		Name:      tokenObject.Spec.Name,
+		RotatedAt: rotatedAt,
+		IssuedAt:  tokenObject.Status.TokenIssuedAt.DeepCopy(),
+		ValueHash: valueHash,
+	})
+	if limit := r.SplunkConfig.PreviousTokensLimit; len(previous) > limit {
+		previous = previous[len(previous)-limit:]
+	}
+	tokenObject.Status.PreviousTokens = previous
+}
+
+// markPreviousTokenDeleted records that the rotated HEC token with the value hash was deleted from Splunk.
+func markPreviousTokenDeleted(tokenObject *stv1alpha1.SplunkToken, valueHash string) {
+	previous := tokenObject.Status.PreviousTokens
+	if n := len(previous); n > 0 && previous[n-1].ValueHash == valueHash {
+		previous[n-1].Deleted = true
+	}
+}
+
+// deletePreviousTokens deletes rotated HEC tokens that are not yet deleted from Splunk and updates
+// their status. Since a rotated token shares its name with the token that replaced it, the token
+// in Splunk is only deleted if the hash of its value matches the rotated token's. A token holding
+// another value means the rotated token is already gone. A rotated token that is still the
+// SplunkToken's current token, because its rotation did not issue a new one, is left for the
+// rotation to retry. Entries recorded without a value hash are deleted by name unless they have
+// the SplunkToken's current name.
+func (r *SplunkTokenReconciler) deletePreviousTokens(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) error {
+	log := logf.FromContext(ctx)
+
+	var changed bool
+	for i, previous := range tokenObject.Status.PreviousTokens {
+		if previous.Deleted {
+			continue
+		}
+		if previous.ValueHash == "" {
+			if previous.Name == tokenObject.Spec.Name {
+				continue
+			}
+		} else {
+			if previous.IssuedAt.Equal(tokenObject.Status.TokenIssuedAt) {
+				continue
+			}
+			token, err := r.tokenManager(tokenObject).GetToken(ctx, previous.Name)
+			if err != nil && !splunkapi.IsNotFound(err) {
+				return err
+			}
+			if err != nil || tokenValueHash(token.Value) != previous.ValueHash {
+				log.Info("previously rotated HEC token no longer exists", "token", previous.Name)
+				tokenObject.Status.PreviousTokens[i].Deleted = true
+				changed = true
+				continue
+			}
+		}
+		log.Info("deleting previously rotated HEC token", "token", previous.Name)
+		if err := r.tokenManager(tokenObject).DeleteToken(ctx, previous.Name); err != nil {
+			return err
+		}
+		tokenObject.Status.PreviousTokens[i].Deleted = true
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.Status().Update(ctx, tokenObject)
+}
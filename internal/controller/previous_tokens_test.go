@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+const rotatedTokenValue = "1c7e2b0f-4d5e-4f6a-9b8c-0d1e2f3a4b5c"
+
+func TestRecordPreviousToken(t *testing.T) {
+	rotatedAt := metav1.NewTime(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	earlier := metav1.NewTime(rotatedAt.Add(-time.Hour))
+	issuedAt := metav1.NewTime(rotatedAt.Add(-24 * time.Hour))
+	currentHash := tokenValueHash(testTokenValue)
+	oldHash := tokenValueHash(rotatedTokenValue)
+
+	tests := []struct {
+		name     string
+		limit    int
+		previous []stv1alpha1.PreviousToken
+		want     []stv1alpha1.PreviousToken
+	}{
+		{
+			name:  "appends rotated token",
+			limit: 3,
+			previous: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: earlier, ValueHash: oldHash, Deleted: true},
+			},
+			want: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: earlier, ValueHash: oldHash, Deleted: true},
+				{Name: "current", RotatedAt: rotatedAt, IssuedAt: &issuedAt, ValueHash: currentHash},
+			},
+		},
+		{
+			name:  "trims oldest tokens to the limit",
+			limit: 2,
+			previous: []stv1alpha1.PreviousToken{
+				{Name: "oldest", RotatedAt: earlier, Deleted: true},
+				{Name: "old", RotatedAt: earlier, Deleted: true},
+			},
+			want: []stv1alpha1.PreviousToken{
+				{Name: "old", RotatedAt: earlier, Deleted: true},
+				{Name: "current", RotatedAt: rotatedAt, IssuedAt: &issuedAt, ValueHash: currentHash},
+			},
+		},
+		{
+			name:  "reuses entry of interrupted rotation",
+			limit: 3,
+			previous: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: earlier, IssuedAt: &issuedAt, ValueHash: currentHash},
+			},
+			want: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: rotatedAt, IssuedAt: &issuedAt, ValueHash: currentHash},
+			},
+		},
+		{
+			name:  "records an undeleted token with another value",
+			limit: 3,
+			previous: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: earlier, ValueHash: oldHash},
+			},
+			want: []stv1alpha1.PreviousToken{
+				{Name: "current", RotatedAt: earlier, ValueHash: oldHash},
+				{Name: "current", RotatedAt: rotatedAt, IssuedAt: &issuedAt, ValueHash: currentHash},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.Name = "current"
+			splunkToken.Status.TokenIssuedAt = &issuedAt
+			splunkToken.Status.PreviousTokens = tt.previous
+			reconciler := SplunkTokenReconciler{SplunkConfig: config.General{PreviousTokensLimit: tt.limit}}
+
+			reconciler.recordPreviousToken(&splunkToken, rotatedAt, currentHash)
+			if !reflect.DeepEqual(splunkToken.Status.PreviousTokens, tt.want) {
+				t.Errorf("expected previous tokens %v but got %v", tt.want, splunkToken.Status.PreviousTokens)
+			}
+		})
+	}
+}
+
+func TestReconcilePreviousTokens(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("records rotated token as deleted", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		issuedAt := metav1.NewTime(time.Now().Add(-2 * time.Hour).Truncate(time.Second))
+		splunkToken.Status.TokenIssuedAt = &issuedAt
+		splunkToken.Status.PreviousTokens = []stv1alpha1.PreviousToken{
+			{Name: "oldest", RotatedAt: issuedAt, Deleted: true},
+			{Name: "old", RotatedAt: issuedAt, Deleted: true},
+		}
+		tokenSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + rotatedTokenValue),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunkClient{create: createSuccess, delete: deleteSuccess, get: getTokenValue(rotatedTokenValue)},
+			SplunkConfig: config.General{
+				TokenMaxAge:         time.Hour,
+				RotationStrategy:    config.RotationStrategySecret,
+				PreviousTokensLimit: 2,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		previous := splunkToken.Status.PreviousTokens
+		if len(previous) != 2 {
+			t.Fatalf("expected previous tokens to be trimmed to 2 but got %v", previous)
+		}
+		if previous[0].Name != "old" {
+			t.Errorf("expected oldest token to be dropped but got %v", previous)
+		}
+		latest := previous[1]
+		if latest.Name != splunkToken.Spec.Name || !latest.Deleted {
+			t.Errorf("expected rotated token %s to be recorded as deleted but got %v", splunkToken.Spec.Name, latest)
+		}
+		if latest.ValueHash != tokenValueHash(rotatedTokenValue) || !latest.IssuedAt.Equal(&issuedAt) {
+			t.Errorf("expected rotated token to be identified by its value and issue time but got %v", latest)
+		}
+	})
+
+	t.Run("deletes undeleted previous tokens recorded by name", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		rotatedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+		splunkToken.Status.PreviousTokens = []stv1alpha1.PreviousToken{
+			{Name: "deleted", RotatedAt: rotatedAt, Deleted: true},
+			{Name: "renamed", RotatedAt: rotatedAt},
+			{Name: splunkToken.Spec.Name, RotatedAt: rotatedAt},
+		}
+		tokenSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour, PreviousTokensLimit: 3},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !slices.Equal(mockSplunk.deletedNames, []string{"renamed"}) {
+			t.Errorf("expected only the renamed token to be deleted but deleted %v", mockSplunk.deletedNames)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		for _, previous := range splunkToken.Status.PreviousTokens {
+			wantDeleted := previous.Name != splunkToken.Spec.Name
+			if previous.Deleted != wantDeleted {
+				t.Errorf("expected token %s deleted %t but got %t", previous.Name, wantDeleted, previous.Deleted)
+			}
+		}
+	})
+
+	t.Run("deletes undeleted previous tokens when finalizing", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		rotatedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+		splunkToken.Status.PreviousTokens = []stv1alpha1.PreviousToken{
+			{Name: "deleted", RotatedAt: rotatedAt, Deleted: true},
+			{Name: "renamed", RotatedAt: rotatedAt},
+		}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour, PreviousTokensLimit: 3},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if want := []string{splunkToken.Spec.Name, "renamed"}; !slices.Equal(mockSplunk.deletedNames, want) {
+			t.Errorf("expected tokens %v to be deleted but deleted %v", want, mockSplunk.deletedNames)
+		}
+	})
+
+	tokenIssuedAt := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	rotatedIssuedAt := metav1.NewTime(tokenIssuedAt.Add(-24 * time.Hour))
+	tests := []struct {
+		name        string
+		issuedAt    metav1.Time
+		splunkValue string
+		wantDeleted bool
+		wantDelete  bool
+	}{
+		{
+			name:        "deletes rotated token still in Splunk",
+			issuedAt:    rotatedIssuedAt,
+			splunkValue: rotatedTokenValue,
+			wantDeleted: true,
+			wantDelete:  true,
+		},
+		{
+			name:        "marks rotated token deleted when its name holds the current token",
+			issuedAt:    rotatedIssuedAt,
+			splunkValue: testTokenValue,
+			wantDeleted: true,
+		},
+		{
+			name:        "keeps rotated token that is still the current token",
+			issuedAt:    tokenIssuedAt,
+			splunkValue: rotatedTokenValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Status.TokenIssuedAt = &tokenIssuedAt
+			splunkToken.Status.PreviousTokens = []stv1alpha1.PreviousToken{{
+				Name:      splunkToken.Spec.Name,
+				RotatedAt: tokenIssuedAt,
+				IssuedAt:  &tt.issuedAt,
+				ValueHash: tokenValueHash(rotatedTokenValue),
+			}}
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+			})
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess, get: getTokenValue(tt.splunkValue)}
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, PreviousTokensLimit: 3},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.deleteCalled != tt.wantDelete {
+				t.Errorf("expected rotated token to be deleted from Splunk %t but deleted %v", tt.wantDelete, mockSplunk.deletedNames)
+			}
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+				t.Fatalf("error getting SplunkToken: %s", err)
+			}
+			if deleted := splunkToken.Status.PreviousTokens[0].Deleted; deleted != tt.wantDeleted {
+				t.Errorf("expected rotated token recorded as deleted %t but got %t", tt.wantDeleted, deleted)
+			}
+		})
+	}
+}
+
+// getTokenValue returns a mock GetToken response for a HEC token with the value.
+func getTokenValue(value string) func() (*splunkapi.HECToken, error) {
+	return func() (*splunkapi.HECToken, error) {
+		return &splunkapi.HECToken{Spec: stv1alpha1.SplunkTokenSpec{Name: "<internal-cluster-id>"}, Value: value}, nil
+	}
+}
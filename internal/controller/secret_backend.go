@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
+)
+
+// A SecretBackend stores the HEC token value issued for a SplunkToken.
+// The default backend writes it to a Secret in the SplunkToken's namespace.
+//
+// Reconcile reads the token value from the Secret named by SecretName, so a backend writing to an
+// external store must arrange for that Secret to be created from the store, e.g. with an
+// ExternalSecret whose target carries the ManagedSecretLabel. While the Secret is missing but
+// TokenStored reports the token as stored, Reconcile waits for it instead of issuing a new token.
+type SecretBackend interface {
+	// StoreToken stores tokenValue for the SplunkToken, replacing any value stored earlier.
+	StoreToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken, tokenValue string) error
+	// TokenStored reports whether a token value is stored for the SplunkToken. It must not rely
+	// on a cache that may lag behind StoreToken, since a token reported missing is revoked.
+	TokenStored(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (bool, error)
+}
+
+// nativeSecretBackend stores token values in Secrets owned by their SplunkToken.
+type nativeSecretBackend struct {
+	r *SplunkTokenReconciler
+}
+
+// StoreToken creates the SplunkToken's Secret, or replaces the existing one if a previous
+// reconcile created it after our cached read.
+func (b nativeSecretBackend) StoreToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken, tokenValue string) error {
+	var tokenSecret corev1.Secret
+	b.r.newSecretObject(tokenObject, tokenValue, &tokenSecret)
+	if err := b.r.setSecretOwner(tokenObject, &tokenSecret); err != nil {
+		return err
+	}
+	err := b.r.Create(ctx, &tokenSecret)
+	metrics.RecordSecretOperation("create", err)
+	if errors.IsAlreadyExists(err) {
+		logf.FromContext(ctx).Info("token Secret already exists, reconciling its contents")
+		err = b.r.reconcileExistingSecret(ctx, tokenObject, &tokenSecret)
+	}
+	return err
+}
+
+// TokenStored reports whether the SplunkToken's Secret exists and is not being deleted,
+// reading it from the API server rather than the cache.
+func (b nativeSecretBackend) TokenStored(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (bool, error) {
+	var reader client.Reader = b.r.Client
+	if b.r.APIReader != nil {
+		reader = b.r.APIReader
+	}
+	key := types.NamespacedName{Namespace: tokenObject.Namespace, Name: tokenSecretName(b.r.SplunkConfig, tokenObject)}
+	var tokenSecret corev1.Secret
+	if err := reader.Get(ctx, key, &tokenSecret); errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return tokenSecret.DeletionTimestamp.IsZero(), nil
+}
+
+// setSecretOwner records the SplunkToken as the owner of a new token Secret according to the
+// SecretLifecycle: as its controller owner reference, or in the SecretOwnerAnnotation.
+func (r *SplunkTokenReconciler) setSecretOwner(tokenObject *stv1alpha1.SplunkToken, secret *corev1.Secret) error {
+	if r.SplunkConfig.SecretLifecycle != config.SecretLifecycleFinalizer {
+		return controllerutil.SetControllerReference(tokenObject, secret, r.Scheme)
+	}
+	metav1.SetMetaDataAnnotation(&secret.ObjectMeta, config.SecretOwnerAnnotation, string(tokenObject.UID))
+	return nil
+}
+
+// issuedForPreviousToken reports whether a token Secret kept by SecretLifecycleFinalizer was issued
+// for an earlier SplunkToken than tokenObject, e.g. one deleted to rotate its token.
+func issuedForPreviousToken(secret *corev1.Secret, tokenObject *stv1alpha1.SplunkToken) bool {
+	owner, found := secret.Annotations[config.SecretOwnerAnnotation]
+	return found && owner != string(tokenObject.UID)
+}
+
+// ownedByPreviousToken reports whether the Secret's controller is a deleted SplunkToken with the
+// same name, which the garbage collector has not yet caught up with.
+func ownedByPreviousToken(secret *corev1.Secret, tokenObject *stv1alpha1.SplunkToken) bool {
+	owner := metav1.GetControllerOf(secret)
+	if owner == nil || owner.Kind != "SplunkToken" || owner.Name != tokenObject.Name {
+		return false
+	}
+	ownerVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+	return err == nil && ownerVersion.Group == stv1alpha1.GroupVersion.Group && owner.UID != tokenObject.UID
+}
+
+// ownedByOtherToken reports whether the Secret records a SplunkToken other than tokenObject as
+// its owner, in its controller owner reference or in the SecretOwnerAnnotation.
+func ownedByOtherToken(secret *corev1.Secret, tokenObject *stv1alpha1.SplunkToken) bool {
+	if owner := metav1.GetControllerOf(secret); owner != nil {
+		return owner.UID != tokenObject.UID
+	}
+	return issuedForPreviousToken(secret, tokenObject)
+}
+
+// tokensForSecret maps a token Secret created by SecretLifecycleFinalizer, which has no owner
+// reference, to the SplunkTokens in its namespace whose Secret has its name, so the Secret is
+// recreated or corrected when it is deleted or modified out of band. Every SplunkToken in a
+// namespace that is not adopted shares the Secret name, so all of them are enqueued.
+func (r *SplunkTokenReconciler) tokensForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	if _, found := secret.GetAnnotations()[config.SecretOwnerAnnotation]; !found ||
+		secret.GetLabels()[config.ManagedSecretLabel] != "true" {
+		return nil
+	}
+	var tokens stv1alpha1.SplunkTokenList
+	if err := r.List(ctx, &tokens, client.InNamespace(secret.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "error listing SplunkTokens of token Secret", "namespace", secret.GetNamespace())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tokenObject := range tokens.Items {
+		if tokenSecretName(r.SplunkConfig, &tokenObject) == secret.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&tokenObject)})
+		}
+	}
+	return requests
+}
+
+func (r *SplunkTokenReconciler) secretBackend() SecretBackend {
+	if r.SecretBackend != nil {
+		return r.SecretBackend
+	}
+	return nativeSecretBackend{r: r}
+}
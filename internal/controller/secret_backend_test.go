@@ -0,0 +1,438 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+type fakeSecretBackend struct {
+	tokens map[string]string
+	err    error
+}
+
+func (b *fakeSecretBackend) StoreToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken, tokenValue string) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.tokens[tokenObject.Namespace+"/"+tokenObject.Name] = tokenValue
+	return nil
+}
+
+func (b *fakeSecretBackend) TokenStored(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (bool, error) {
+	if b.err != nil {
+		return false, b.err
+	}
+	_, found := b.tokens[tokenObject.Namespace+"/"+tokenObject.Name]
+	return found, nil
+}
+
+func TestReconcileSecretBackend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("hands token value to configured backend", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		backend := &fakeSecretBackend{tokens: map[string]string{}}
+		reconciler := SplunkTokenReconciler{
+			Client:        fakeClient,
+			Scheme:        scheme,
+			SplunkApi:     &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig:  config.General{TokenMaxAge: time.Hour},
+			SecretBackend: backend,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if got := backend.tokens[request.String()]; got != testTokenValue {
+			t.Errorf("expected backend to store token value %s but got %q", testTokenValue, got)
+		}
+		var secret corev1.Secret
+		secretKey := types.NamespacedName{Namespace: request.Namespace, Name: config.OwnedObjectName}
+		if err := fakeClient.Get(t.Context(), secretKey, &secret); !kerrors.IsNotFound(err) {
+			t.Errorf("expected no native Secret but got %v", err)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if splunkToken.Status.TokenIssuedAt == nil {
+			t.Error("expected token issue time to be recorded")
+		}
+	})
+
+	t.Run("waits for the Secret of a token stored in the backend", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		backend := &fakeSecretBackend{tokens: map[string]string{}}
+		mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+		reconciler := SplunkTokenReconciler{
+			Client:        fakeClient,
+			Scheme:        scheme,
+			SplunkApi:     &mockSplunk,
+			SplunkConfig:  config.General{TokenMaxAge: time.Hour},
+			SecretBackend: backend,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		mockSplunk.createCalled = false
+
+		// the Secret synced from the backend does not exist yet
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.createCalled || mockSplunk.deleteCalled {
+			t.Error("should not revoke or issue the HEC token while the backend stores it")
+		}
+		if result.RequeueAfter != defaultTerminatingSecretRequeueInterval {
+			t.Errorf("expected requeue after %s but got %s", defaultTerminatingSecretRequeueInterval, result.RequeueAfter)
+		}
+	})
+
+	t.Run("returns backend errors", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:        fakeClient,
+			Scheme:        scheme,
+			SplunkApi:     &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig:  config.General{TokenMaxAge: time.Hour},
+			SecretBackend: &fakeSecretBackend{err: errors.New("store unavailable")},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error from secret backend")
+		}
+	})
+}
+
+func TestReconcileSecretLifecycle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	const oldTokenValue = "00000000-0000-0000-0000-000000000000"
+	newSplunkToken := func() stv1alpha1.SplunkToken {
+		splunkToken := testSplunkToken()
+		splunkToken.UID = "current-uid"
+		return splunkToken
+	}
+
+	tests := []struct {
+		name       string
+		lifecycle  string
+		secretUID  string
+		wantCreate bool
+		wantOwned  bool
+		wantValue  string
+	}{
+		{
+			name:       "owner reference lifecycle sets controller reference",
+			lifecycle:  config.SecretLifecycleOwnerReference,
+			wantCreate: true,
+			wantOwned:  true,
+			wantValue:  testTokenValue,
+		},
+		{
+			name:       "finalizer lifecycle records owner in annotation",
+			lifecycle:  config.SecretLifecycleFinalizer,
+			wantCreate: true,
+			wantValue:  testTokenValue,
+		},
+		{
+			name:       "replaces Secret issued for previous SplunkToken",
+			lifecycle:  config.SecretLifecycleFinalizer,
+			secretUID:  "previous-uid",
+			wantCreate: true,
+			wantValue:  testTokenValue,
+		},
+		{
+			name:      "keeps Secret issued for current SplunkToken",
+			lifecycle: config.SecretLifecycleFinalizer,
+			secretUID: "current-uid",
+			wantValue: oldTokenValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := newSplunkToken()
+			objects := []runtime.Object{&splunkToken}
+			if tt.secretUID != "" {
+				tokenSecret := testTokenSecret(map[string][]byte{
+					"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + oldTokenValue),
+				})
+				tokenSecret.Annotations = map[string]string{config.SecretOwnerAnnotation: tt.secretUID}
+				objects = append(objects, &tokenSecret)
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteSuccess}
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, SecretLifecycle: tt.lifecycle},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			tokenSecret := getTokenSecret(t, fakeClient)
+			if value, _ := tokenValueFromSecret(&tokenSecret); value != tt.wantValue {
+				t.Errorf("expected Secret to contain token value %s but got %s", tt.wantValue, value)
+			}
+			if owned := len(tokenSecret.OwnerReferences) > 0; owned != tt.wantOwned {
+				t.Errorf("expected Secret owner references %t but got %v", tt.wantOwned, tokenSecret.OwnerReferences)
+			}
+			if tt.wantOwned {
+				return
+			}
+			if owner := tokenSecret.Annotations[config.SecretOwnerAnnotation]; owner != string(splunkToken.UID) {
+				t.Errorf("expected Secret owner annotation %s but got %q", splunkToken.UID, owner)
+			}
+		})
+	}
+}
+
+func TestReconcileFinalizeKeptSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name        string
+		allowDelete bool
+		secretUID   string
+		wantDeleted bool
+	}{
+		{
+			name:        "deletes Secret of deleted SplunkToken",
+			secretUID:   "current-uid",
+			wantDeleted: true,
+		},
+		{
+			name:        "keeps Secret of SplunkToken deleted for rotation",
+			allowDelete: true,
+			secretUID:   "current-uid",
+		},
+		{
+			name:      "keeps Secret issued for another SplunkToken",
+			secretUID: "recreated-uid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.UID = "current-uid"
+			splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			if tt.allowDelete {
+				splunkToken.Annotations = map[string]string{config.AllowDeleteAnnotation: "true"}
+			}
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+			})
+			tokenSecret.Annotations = map[string]string{config.SecretOwnerAnnotation: tt.secretUID}
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess},
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, SecretLifecycle: config.SecretLifecycleFinalizer},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			err := fakeClient.Get(t.Context(), client.ObjectKeyFromObject(&tokenSecret), &tokenSecret)
+			if deleted := kerrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("expected Secret deleted %t but got %v", tt.wantDeleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileStaleOwnerSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	const oldTokenValue = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name        string
+		policy      string
+		ownerUID    types.UID
+		wantCreate  bool
+		wantRequeue time.Duration
+		wantValue   string
+	}{
+		{
+			name:       "reissues token when Secret is owned by a deleted SplunkToken",
+			ownerUID:   "previous-uid",
+			wantCreate: true,
+			wantValue:  testTokenValue,
+		},
+		{
+			name:        "waits for garbage collection of Secret owned by a deleted SplunkToken",
+			policy:      config.StaleSecretPolicyWait,
+			ownerUID:    "previous-uid",
+			wantRequeue: defaultTerminatingSecretRequeueInterval,
+			wantValue:   oldTokenValue,
+		},
+		{
+			name:      "keeps Secret owned by current SplunkToken",
+			ownerUID:  "current-uid",
+			wantValue: oldTokenValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.UID = "current-uid"
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + oldTokenValue),
+			})
+			owner := splunkToken.DeepCopy()
+			owner.UID = tt.ownerUID
+			if err := controllerutil.SetControllerReference(owner, &tokenSecret, scheme); err != nil {
+				t.Fatalf("error setting Secret owner: %s", err)
+			}
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, StaleSecretPolicy: tt.policy},
+			}
+
+			result, err := reconciler.Reconcile(t.Context(), request)
+			if err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if result.RequeueAfter != tt.wantRequeue {
+				t.Errorf("expected requeue after %s but got %s", tt.wantRequeue, result.RequeueAfter)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			tokenSecret = getTokenSecret(t, fakeClient)
+			if value, _ := tokenValueFromSecret(&tokenSecret); value != tt.wantValue {
+				t.Errorf("expected Secret to contain token value %s but got %s", tt.wantValue, value)
+			}
+			if tt.wantCreate && !metav1.IsControlledBy(&tokenSecret, &splunkToken) {
+				t.Errorf("expected new Secret to be owned by the current SplunkToken but got %v", tokenSecret.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestTokensForSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkToken := testSplunkToken()
+	otherNamespaceToken := testSplunkToken()
+	otherNamespaceToken.Namespace = "other-namespace"
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(&splunkToken, &otherNamespaceToken).
+		Build()
+	reconciler := SplunkTokenReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		SplunkConfig: config.General{SecretLifecycle: config.SecretLifecycleFinalizer},
+	}
+
+	keptSecret := testTokenSecret(nil)
+	keptSecret.Annotations = map[string]string{config.SecretOwnerAnnotation: "deleted-uid"}
+	unmanagedSecret := *keptSecret.DeepCopy()
+	unmanagedSecret.Labels = nil
+	otherSecret := *keptSecret.DeepCopy()
+	otherSecret.Name = "other-secret"
+
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		want   []reconcile.Request
+	}{
+		{
+			name:   "enqueues SplunkTokens in the namespace of a kept token Secret",
+			secret: &keptSecret,
+			want:   []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(&splunkToken)}},
+		},
+		{
+			name:   "ignores Secret without managed label",
+			secret: &unmanagedSecret,
+		},
+		{
+			name:   "ignores Secret with another name",
+			secret: &otherSecret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconciler.tokensForSecret(t.Context(), tt.secret)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected requests %v but got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -17,53 +17,185 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
 	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/audit"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
 	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
 )
 
+// tokenValuePattern matches the GUID format of HEC token values issued by Splunk.
+var tokenValuePattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+const (
+	// defaultForbiddenRequeueInterval is used when ForbiddenRequeueInterval is not configured.
+	defaultForbiddenRequeueInterval = 30 * time.Minute
+	// defaultMaintenanceRequeueInterval is used when MaintenanceRequeueInterval is not configured.
+	defaultMaintenanceRequeueInterval = time.Hour
+	// defaultDeleteRetryBackoff is used when DeleteRetryBackoff is not configured.
+	defaultDeleteRetryBackoff = time.Second
+	// defaultTerminatingSecretRequeueInterval is used when TerminatingSecretRequeueInterval is not configured.
+	defaultTerminatingSecretRequeueInterval = 5 * time.Second
+)
+
+// tokenLinePattern matches the token setting in a generated outputs.conf.
+var tokenLinePattern = regexp.MustCompile(`(?m)^httpEventCollectorToken = (.*)$`)
+
 // SplunkTokenReconciler reconciles a SplunkToken object
 type SplunkTokenReconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
 	SplunkApi    splunkapi.TokenManager
 	SplunkConfig config.General
+	Summary      *ActivitySummary
+
+	// SplunkInstances are the clients of Splunk instances other than SplunkConfig.SplunkInstance,
+	// by instance name, which a SplunkToken may select with spec.splunkInstance.
+	SplunkInstances map[string]splunkapi.TokenManager
+
+	// Clock provides the current time for rotation decisions. Defaults to the real clock when nil.
+	Clock clock.PassiveClock
+
+	// SecretBackend stores issued token values. Defaults to Secrets owned by the SplunkToken when nil.
+	SecretBackend SecretBackend
+
+	// APIReader reads from the API server instead of the cache of Client, to confirm a token
+	// Secret is gone before its HEC token is revoked. Defaults to Client when nil.
+	APIReader client.Reader
+
+	// Audit records token creation, rotation, and deletion. Nothing is recorded when nil.
+	Audit *audit.Logger
+
+	// Quota is the periodic count of HEC tokens on the default Splunk instance, used to warn
+	// before creating a token near the instance's token quota. No warning is recorded when nil.
+	Quota *TokenQuota
 }
 
 // +kubebuilder:rbac:groups=splunktoken.managed.openshift.io,resources=splunktokens,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=splunktoken.managed.openshift.io,resources=splunktokens/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=splunktoken.managed.openshift.io,resources=splunktokens/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=create;list;watch
-// +kubebuilder:rbac:groups="",resources=secrets,resourceNames=splunk-hec-token,verbs=get;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;patch;delete
 
 // Reconcile takes the following actions depending on the state of the SplunkToken:
+//   - If reconciliation is paused in the operator config, nothing is done.
 //   - If the SplunkToken no longer exists there is nothing to do and Reconcile ends.
-//   - If the SplunkToken has a deletion timestamp, the HEC Token is deleted from the Splunk server.
+//   - During a configured Splunk maintenance window an event is recorded and the SplunkToken
+//     is requeued for the end of the window. A request failing with a maintenance error code
+//     is requeued after MaintenanceRequeueInterval the same way.
+//   - If the SplunkToken has a deletion timestamp, the HEC Token is deleted from the Splunk server,
+//     along with rotated HEC tokens recorded in its status that were not yet deleted.
+//     If configured, the token Secret is deleted as well. With the finalizer Secret lifecycle
+//     it is deleted unless the SplunkToken was deleted with the allow-delete annotation to rotate it.
+//     Legacy finalizers are removed along with the current finalizer.
+//     Once FinalizerTimeout has passed, the finalizer is removed even if the HEC token
+//     could not be deleted, so the SplunkToken is not stuck terminating.
+//     If the SplunkToken selects a Splunk instance the operator no longer has a client for,
+//     the finalizer is removed with an event and the HEC token is left to be deleted manually.
+//     Up to ConcurrentDeletions SplunkTokens are finalized at the same time, while the
+//     remaining steps run for one SplunkToken at a time.
+//   - If the SplunkToken selects a Splunk instance the operator has no client for,
+//     an event is recorded and nothing more is done.
+//   - If the SplunkToken's namespace is not in AllowedNamespaces, when set, or is in
+//     DeniedNamespaces, nothing more is done.
+//   - If the SplunkToken's token name is empty, an event is recorded and nothing more is done.
+//   - Finalizers listed in LegacyFinalizers are replaced with the current finalizer.
+//   - If a DuplicateTokenPolicy is configured, a SplunkToken not named TokenObjectName
+//     is skipped unless the policy adopts it. The decision is recorded as an event.
+//     An adopted SplunkToken's Secret name is suffixed with the SplunkToken's name.
 //   - If the CreationTimestamp of the SplunkToken is older than the configured MaxAge,
 //     the SplunkToken object is deleted so the token can be rotated.
+//     The max-age annotation overrides MaxAge for a single SplunkToken.
+//     With the secret rotation strategy, the HEC token is instead reissued once its
+//     value is older than MaxAge and only the Secret is replaced.
+//     Rotation is postponed while the token was rotated less than MinRotationInterval ago.
+//     A MaxAge of zero disables rotation.
+//   - Rotated HEC tokens recorded in the SplunkToken's status that were not
+//     deleted from the Splunk server are deleted.
 //   - If there is no Secret object for the HEC token,
 //     a new token is created on the Splunk server.
+//     If a token was already issued its Secret was deleted,
+//     so the old token is deleted first to issue a new value. Since the cache may lag
+//     behind a newly created Secret, this waits while the SecretBackend still stores the token.
+//     Otherwise, with ReuseExistingTokens, a HEC token that already exists on the Splunk
+//     server with the same name is updated to match and stored instead.
 //     The Reconciler stores the token value in a Secret,
 //     and a SyncSet is created to push the token to the managed cluster.
-func (r *SplunkTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+//   - If the Secret is being deleted, the SplunkToken is requeued
+//     so the Secret is recreated once it is gone.
+//   - If the Secret was kept by the finalizer Secret lifecycle for a previous SplunkToken,
+//     a new token is created and the Secret is replaced.
+//   - If the Secret is still owned by a deleted SplunkToken of the same name, it is deleted and
+//     a new token is created, or with the wait StaleSecretPolicy the SplunkToken is requeued
+//     until the garbage collector has deleted it.
+//   - If verification is enabled and the HEC token no longer exists on the
+//     Splunk server, a new token is created and its Secret is replaced.
+//     The SplunkToken is requeued to be verified again after VerifyInterval.
+//   - If index updates are enabled and the indexes, sourcetype, description or tags of the
+//     HEC token on the Splunk server differ from the SplunkToken's, the HEC token is updated.
+//     Updating keeps the token value, so unlike a missing HEC token it leaves the Secret as is.
+//   - If the SplunkToken was disabled or enabled since the HEC token was last updated,
+//     the HEC token is updated to match.
+//   - If reissuing empty tokens is enabled and the Secret holds an empty token value,
+//     the token is reissued and the Secret is replaced.
+//   - If the Secret's contents do not match the configured format,
+//     the Secret is regenerated with the existing token value.
+//   - The Splunk instance and collector URI of the HEC token are recorded in status.
+//   - If a metadata ConfigMap is configured, the token's non-secret
+//     metadata is published to it.
+func (r *SplunkTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := logf.FromContext(ctx).WithValues("namespace", req.Namespace)
+	if r.SplunkConfig.Paused {
+		log.Info("reconciliation is paused, skipping splunk token")
+		return ctrl.Result{}, nil
+	}
 	log.Info("reconciling splunk token")
+	if r.SplunkConfig.ReconcileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.SplunkConfig.ReconcileTimeout)
+		defer cancel()
+	}
+	if r.SplunkConfig.RetryBudget > 0 {
+		ctx = splunkapi.WithRetryBudget(ctx, r.SplunkConfig.RetryBudget)
+	}
+	defer func() {
+		if err != nil {
+			r.Summary.Record(OutcomeErrored)
+		}
+	}()
 
 	var tokenObject stv1alpha1.SplunkToken
-	err := r.Get(ctx, req.NamespacedName, &tokenObject)
+	err = r.Get(ctx, req.NamespacedName, &tokenObject)
 	if errors.IsNotFound(err) {
 		log.Info("token not found")
 		return ctrl.Result{}, nil
@@ -71,92 +203,1116 @@ func (r *SplunkTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		log.Error(err, "error retrieving SplunkToken")
 		return ctrl.Result{}, err
 	}
+	unlock := tokenLocks.Lock(tokenObject.Spec.Name)
+	defer unlock()
+
+	if window, found := r.maintenanceWindow(); found {
+		log.Info("Splunk maintenance window in progress, postponing token operations", "until", window.End)
+		r.Recorder.Eventf(&tokenObject, corev1.EventTypeNormal, "SplunkMaintenance",
+			"Splunk is under scheduled maintenance, postponing token operations until %s", window.End.UTC().Format(time.RFC3339))
+		return ctrl.Result{RequeueAfter: window.End.Sub(r.now())}, nil
+	}
 
 	if !tokenObject.DeletionTimestamp.IsZero() {
 		log.Info("SplunkToken has deletion timestamp, deleting HEC token from Splunk server")
-		if err := r.SplunkApi.DeleteToken(ctx, tokenObject.Spec.Name); err != nil {
+		if r.tokenManager(&tokenObject) == nil {
+			log.Info("SplunkToken selects a Splunk instance that is no longer configured, removing finalizer without deleting HEC token",
+				"instance", tokenObject.Spec.SplunkInstance)
+			metrics.OrphanedTokens.Inc()
+			r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "TokenOrphaned",
+				"Splunk instance %s is not configured in the operator, HEC token %s must be deleted manually",
+				tokenObject.Spec.SplunkInstance, tokenObject.Spec.Name)
+		} else if err := r.deleteTokenWithRetry(ctx, &tokenObject); err != nil && !r.finalizerTimedOut(&tokenObject) {
 			log.Error(err, "error deleting HEC token from Splunk")
+			return r.splunkErrorResult(&tokenObject, err)
+		} else if err != nil {
+			log.Error(err, "finalizer timeout exceeded, removing finalizer without deleting HEC token from Splunk",
+				"timeout", r.SplunkConfig.FinalizerTimeout)
+			metrics.OrphanedTokens.Inc()
+			r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "TokenOrphaned",
+				"HEC token %s could not be deleted from Splunk within %s and must be deleted manually: %v",
+				tokenObject.Spec.Name, r.SplunkConfig.FinalizerTimeout, err)
+		}
+		if r.tokenManager(&tokenObject) != nil {
+			if err := r.deletePreviousTokens(ctx, &tokenObject); err != nil && !r.finalizerTimedOut(&tokenObject) {
+				log.Error(err, "error deleting previously rotated HEC tokens from Splunk")
+				return r.splunkErrorResult(&tokenObject, err)
+			} else if err != nil {
+				log.Error(err, "finalizer timeout exceeded, removing finalizer without deleting previously rotated HEC tokens",
+					"timeout", r.SplunkConfig.FinalizerTimeout)
+				metrics.OrphanedTokens.Inc()
+				r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "TokenOrphaned",
+					"previously rotated HEC tokens could not be deleted from Splunk within %s and must be deleted manually: %v",
+					r.SplunkConfig.FinalizerTimeout, err)
+			}
+		}
+		if r.SplunkConfig.DeleteSecretOnFinalize || !r.keepsSecretForRotation(&tokenObject) {
+			if err := r.deleteTokenSecret(ctx, &tokenObject); err != nil {
+				log.Error(err, "error deleting token Secret")
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.removeTokenMetadata(ctx, &tokenObject); err != nil {
+			log.Error(err, "error removing token metadata")
 			return ctrl.Result{}, err
 		}
-		controllerutil.RemoveFinalizer(&tokenObject, config.TokenFinalizer)
-		if err := r.Update(ctx, &tokenObject); err != nil {
+		removeFinalizers := func(t *stv1alpha1.SplunkToken) {
+			controllerutil.RemoveFinalizer(t, config.TokenFinalizer)
+			for _, legacyFinalizer := range r.SplunkConfig.LegacyFinalizers {
+				controllerutil.RemoveFinalizer(t, legacyFinalizer)
+			}
+		}
+		if err := r.updateTokenObject(ctx, &tokenObject, removeFinalizers); err != nil {
 			log.Error(err, "error removing finalizer")
 			return ctrl.Result{}, err
 		}
+		r.Summary.Record(OutcomeDeleted)
+		r.recordAudit(ctx, audit.ActionDelete, &tokenObject)
+		return ctrl.Result{}, nil
+	}
+	if r.tokenManager(&tokenObject) == nil {
+		log.Info("SplunkToken selects a Splunk instance that is not configured", "instance", tokenObject.Spec.SplunkInstance)
+		r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "UnknownSplunkInstance",
+			"Splunk instance %s is not configured in the operator", tokenObject.Spec.SplunkInstance)
+		return ctrl.Result{}, nil
+	}
+
+	if !namespaceManaged(r.SplunkConfig, tokenObject.Namespace) {
+		log.Info("SplunkToken namespace is not managed by the operator config, skipping")
+		return ctrl.Result{}, nil
+	}
+
+	if !hasTokenName(&tokenObject) {
+		log.Info("SplunkToken has an empty token name, not creating HEC token")
+		r.Recorder.Event(&tokenObject, corev1.EventTypeWarning, "MissingTokenName",
+			"SplunkToken must set a non-empty spec.name")
+		return ctrl.Result{}, nil
+	}
+
+	if r.hasLegacyFinalizer(&tokenObject) {
+		log.Info("replacing legacy finalizer on SplunkToken")
+		if err := r.updateTokenObject(ctx, &tokenObject, r.migrateFinalizers); err != nil {
+			log.Error(err, "error replacing legacy finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !r.allowDuplicateToken(&tokenObject) {
+		log.Info("SplunkToken is not the canonical SplunkToken of the namespace, skipping",
+			"name", tokenObject.Name, "policy", r.SplunkConfig.DuplicateTokenPolicy)
 		return ctrl.Result{}, nil
 	}
 
-	currentTime := time.Now()
-	tokenRotationDeadline := tokenObject.CreationTimestamp.Add(r.SplunkConfig.TokenMaxAge)
-	if currentTime.After(tokenRotationDeadline) {
+	maxAge, err := tokenMaxAge(r.SplunkConfig, &tokenObject)
+	if err != nil {
+		log.Info("ignoring invalid max age annotation, using configured TokenMaxAge", "error", err.Error())
+		r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "InvalidMaxAge",
+			"ignoring %s annotation: %v", config.MaxAgeAnnotation, err)
+	}
+	// a zero TokenMaxAge disables rotation rather than expiring every token immediately
+	currentTime := r.now()
+	tokenRotationDeadline := rotationDeadline(r.SplunkConfig, &tokenObject)
+	if maxAge > 0 && currentTime.After(tokenRotationDeadline) {
+		if wait := r.rotationWait(&tokenObject, currentTime); wait > 0 {
+			log.Info("HEC token was rotated recently, postponing rotation", "retryAfter", wait)
+			r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "RotationTooSoon",
+				"HEC token was rotated less than %s ago, postponing rotation", r.SplunkConfig.MinRotationInterval)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		if r.SplunkConfig.RotationStrategy == config.RotationStrategySecret {
+			log.Info("HEC token is stale, rotating token Secret")
+			return r.rotateTokenSecret(ctx, &tokenObject)
+		}
 		log.Info("SplunkToken is stale, rotating")
+		if tokenObject.Annotations[config.AllowDeleteAnnotation] != "true" {
+			allowDelete := func(t *stv1alpha1.SplunkToken) {
+				metav1.SetMetaDataAnnotation(&t.ObjectMeta, config.AllowDeleteAnnotation, "true")
+			}
+			if err := r.updateTokenObject(ctx, &tokenObject, allowDelete); err != nil {
+				log.Error(err, "error allowing deletion of SplunkToken object")
+				return ctrl.Result{}, err
+			}
+		}
 		if err := r.Delete(ctx, &tokenObject); err != nil {
 			log.Error(err, "error deleting SplunkToken object")
 			return ctrl.Result{}, err
 		}
+		r.Summary.Record(OutcomeRotated)
+		r.recordAudit(ctx, audit.ActionRotate, &tokenObject)
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.deletePreviousTokens(ctx, &tokenObject); err != nil {
+		log.Error(err, "error deleting previously rotated HEC tokens")
+		return r.splunkErrorResult(&tokenObject, err)
+	}
+
 	ownedObjectKey := types.NamespacedName{
 		Namespace: req.Namespace,
-		Name:      config.OwnedObjectName,
+		Name:      tokenSecretName(r.SplunkConfig, &tokenObject),
 	}
 	var tokenSecret corev1.Secret
-	if err := r.Get(ctx, ownedObjectKey, &tokenSecret); errors.IsNotFound(err) {
-		log.Info("token Secret not found, requesting new token from Splunk")
-		if controllerutil.AddFinalizer(&tokenObject, config.TokenFinalizer) {
-			if err := r.Update(ctx, &tokenObject); err != nil {
+	err = r.Get(ctx, ownedObjectKey, &tokenSecret)
+	if errors.IsNotFound(err) {
+		if renamed, err := r.renameTokenSecret(ctx, &tokenObject); err != nil {
+			log.Error(err, "error renaming token Secret")
+			return ctrl.Result{}, err
+		} else if renamed {
+			log.Info("token Secret renamed", "secret", ownedObjectKey.Name)
+			return ctrl.Result{}, nil
+		}
+		if tokenObject.Status.TokenIssuedAt != nil {
+			// The cache commonly lags behind the Secret created with the token, e.g. on the reconcile
+			// triggered by the status update of issuing it, and an external backend's Secret only
+			// appears once it is synced, so ask the backend whether the token is really gone.
+			if stored, err := r.secretBackend().TokenStored(ctx, &tokenObject); err != nil {
+				log.Error(err, "unable to look up stored HEC token")
 				return ctrl.Result{}, err
+			} else if stored {
+				requeueAfter := r.terminatingSecretRequeueInterval()
+				log.Info("HEC token is stored but its Secret is not yet in the cache, waiting for it", "retryAfter", requeueAfter)
+				return ctrl.Result{RequeueAfter: requeueAfter}, nil
 			}
-			log.Info("finalizer added to SplunkToken")
 		}
-		tokenOptions := splunkapi.HECToken{
-			Spec: tokenObject.Spec,
+		log.Info("token Secret not found, requesting new token from Splunk")
+		return r.createTokenSecret(logf.IntoContext(ctx, log), &tokenObject)
+	} else if err != nil {
+		log.Error(err, "unable to fetch token Secret")
+		return ctrl.Result{}, err
+	}
+	if !tokenSecret.DeletionTimestamp.IsZero() {
+		// the Secret still exists while it is garbage collected but will not hold a usable token
+		requeueAfter := r.terminatingSecretRequeueInterval()
+		log.Info("token Secret is being deleted, waiting to recreate it", "retryAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if isManagedSecret(&tokenSecret, &tokenObject) && issuedForPreviousToken(&tokenSecret, &tokenObject) {
+		// the Secret was kept by the finalizer lifecycle while the SplunkToken was recreated
+		log.Info("token Secret was issued for a previous SplunkToken, issuing a new token")
+		return r.issueToken(logf.IntoContext(ctx, log), &tokenObject)
+	}
+	if ownedByPreviousToken(&tokenSecret, &tokenObject) {
+		// the SplunkToken was recreated before the garbage collector deleted its Secret,
+		// whose token value was revoked when the previous SplunkToken was finalized
+		if r.SplunkConfig.StaleSecretPolicy == config.StaleSecretPolicyWait {
+			requeueAfter := r.terminatingSecretRequeueInterval()
+			log.Info("token Secret is owned by a deleted SplunkToken, waiting for it to be garbage collected",
+				"retryAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		log.Info("token Secret is owned by a deleted SplunkToken, issuing a new token")
+		err := r.Delete(ctx, &tokenSecret)
+		if errors.IsNotFound(err) {
+			err = nil
 		}
-		hecToken, err := r.SplunkApi.CreateToken(ctx, tokenOptions)
+		metrics.RecordSecretOperation("delete", err)
 		if err != nil {
-			log.Error(err, "error creating HEC token")
+			log.Error(err, "error deleting token Secret of deleted SplunkToken")
 			return ctrl.Result{}, err
 		}
-		r.newSecretObject(req.Namespace, hecToken.Value, &tokenSecret)
-		if err := controllerutil.SetControllerReference(&tokenObject, &tokenSecret, r.Scheme); err != nil {
+		return r.createTokenSecret(logf.IntoContext(ctx, log), &tokenObject)
+	}
+
+	var tokenUpdated bool
+	if r.SplunkConfig.VerifyInterval > 0 || r.SplunkConfig.UpdateIndexes {
+		liveToken, err := r.tokenManager(&tokenObject).GetToken(ctx, tokenObject.Spec.Name)
+		if splunkapi.IsNotFound(err) {
+			log.Info("HEC token no longer exists in Splunk, issuing a new token")
+			result, err := r.issueToken(logf.IntoContext(ctx, log), &tokenObject)
+			if err == nil && result.IsZero() {
+				result.RequeueAfter = r.SplunkConfig.VerifyInterval
+			}
+			return result, err
+		} else if err != nil {
+			log.Error(err, "error verifying HEC token in Splunk")
+			return r.splunkErrorResult(&tokenObject, err)
+		}
+		if r.SplunkConfig.UpdateIndexes && (!splunkapi.TokenMatchesSpec(liveToken, r.tokenSpec(&tokenObject)) ||
+			!splunkapi.TokenTagsMatch(liveToken, r.tokenTags(&tokenObject))) {
+			log.Info("HEC token differs from SplunkToken, updating token in Splunk")
+			if _, err := r.tokenManager(&tokenObject).UpdateToken(ctx, r.updatedToken(&tokenObject)); err != nil {
+				log.Error(err, "error updating HEC token")
+				return r.splunkErrorResult(&tokenObject, err)
+			}
+			tokenUpdated = true
+		}
+	}
+	if tokenObject.Spec.Disabled != tokenObject.Status.Disabled {
+		if !tokenUpdated {
+			log.Info("applying SplunkToken enablement to HEC token in Splunk", "disabled", tokenObject.Spec.Disabled)
+			if _, err := r.tokenManager(&tokenObject).UpdateToken(ctx, r.updatedToken(&tokenObject)); err != nil {
+				log.Error(err, "error updating HEC token enablement")
+				return r.splunkErrorResult(&tokenObject, err)
+			}
+		}
+		tokenObject.Status.Disabled = tokenObject.Spec.Disabled
+		if err := r.Status().Update(ctx, &tokenObject); err != nil {
+			log.Error(err, "error updating SplunkToken status")
 			return ctrl.Result{}, err
 		}
+	}
+
+	if !isManagedSecret(&tokenSecret, &tokenObject) {
+		log.Info("token Secret exists but is not managed by the operator, leaving it unchanged")
+		r.Recorder.Eventf(&tokenObject, corev1.EventTypeWarning, "SecretConflict",
+			"Secret %s exists but is not managed by the operator", ownedObjectKey.Name)
+		return ctrl.Result{}, nil
+	}
 
-		if err := r.Create(ctx, &tokenSecret); err != nil {
-			log.Error(err, "error creating Secret object")
+	tokenValue, found := tokenValueFromSecret(&tokenSecret)
+	if !found {
+		log.Info("unable to read HEC token value from Secret, leaving it unchanged")
+		return ctrl.Result{}, nil
+	}
+	if strings.TrimSpace(tokenValue) == "" && r.SplunkConfig.ReissueEmptyTokens {
+		log.Info("token Secret has an empty HEC token value, reissuing token")
+		return r.issueToken(logf.IntoContext(ctx, log), &tokenObject)
+	}
+	var wantSecret corev1.Secret
+	r.newSecretObject(&tokenObject, tokenValue, &wantSecret)
+	if !maps.EqualFunc(tokenSecret.Data, wantSecret.Data, bytes.Equal) {
+		log.Info("token Secret format is out of date, regenerating")
+		if err := r.setSecretOwner(&tokenObject, &wantSecret); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.updateSecret(ctx, &tokenSecret, &wantSecret); err != nil {
+			log.Error(err, "error regenerating token Secret")
 			return ctrl.Result{}, err
 		}
+	} else if r.SplunkConfig.MutableSecrets && !secretMetadataCurrent(&tokenSecret, &wantSecret) {
+		log.Info("token Secret labels or annotations are out of date, updating them in place")
+		if err := r.patchSecretMetadata(ctx, &tokenSecret, &wantSecret); err != nil {
+			log.Error(err, "error updating token Secret metadata")
+			return ctrl.Result{}, err
+		}
+	}
+	if setTargetStatus(&tokenObject, r.SplunkConfig) {
+		log.Info("recording Splunk instance of HEC token in status", "instance", tokenObject.Status.SplunkInstance)
+		if err := r.Status().Update(ctx, &tokenObject); err != nil {
+			log.Error(err, "error updating SplunkToken status")
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.publishTokenMetadata(ctx, &tokenObject); err != nil {
+		log.Error(err, "error publishing token metadata")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.SplunkConfig.VerifyInterval}, nil
+}
+
+// createTokenSecret creates a new HEC token on the Splunk server
+// and stores its value in a new Secret owned by the SplunkToken.
+func (r *SplunkTokenReconciler) createTokenSecret(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	if allowed, err := r.withinNamespaceLimit(ctx, tokenObject); err != nil {
+		log.Error(err, "error counting SplunkTokens in namespace")
+		return ctrl.Result{}, err
+	} else if !allowed {
+		log.Info("namespace token limit exceeded, not creating HEC token", "limit", r.SplunkConfig.MaxTokensPerNamespace)
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeWarning, "TokenLimitExceeded",
+			"namespace already has the maximum of %d SplunkTokens", r.SplunkConfig.MaxTokensPerNamespace)
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(tokenObject, config.TokenFinalizer) {
+		addFinalizer := func(t *stv1alpha1.SplunkToken) { controllerutil.AddFinalizer(t, config.TokenFinalizer) }
+		if err := r.updateTokenObject(ctx, tokenObject, addFinalizer); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("finalizer added to SplunkToken")
+	}
+	if r.missingRequiredIndex(ctx, tokenObject) {
+		return ctrl.Result{}, nil
+	}
+	if tokenObject.Status.TokenIssuedAt != nil {
+		// The Secret for an issued token was deleted. Splunk returns the existing value
+		// when creating a token that already exists, so delete it first to issue a fresh value.
+		log.Info("deleting existing HEC token so a new value is issued")
+		if err := r.tokenManager(tokenObject).DeleteToken(ctx, tokenObject.Spec.Name); err != nil {
+			log.Error(err, "error deleting existing HEC token from Splunk")
+			return r.splunkErrorResult(tokenObject, err)
+		}
+	} else if r.SplunkConfig.ReuseExistingTokens {
+		if result, handled, err := r.reuseExistingToken(ctx, tokenObject); handled {
+			return result, err
+		}
+	}
+	return r.issueToken(ctx, tokenObject)
+}
+
+// reuseExistingToken stores the value of a HEC token that already exists in Splunk under the
+// SplunkToken's token name, e.g. because the SplunkToken was recreated, instead of issuing a new
+// token. The existing token is updated first if its indexes differ from the SplunkToken's.
+// It reports whether the reconcile was handled, which it is not when there is no token to reuse.
+func (r *SplunkTokenReconciler) reuseExistingToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (ctrl.Result, bool, error) {
+	log := logf.FromContext(ctx)
+
+	liveToken, err := r.tokenManager(tokenObject).GetToken(ctx, tokenObject.Spec.Name)
+	if splunkapi.IsNotFound(err) {
+		return ctrl.Result{}, false, nil
 	} else if err != nil {
-		log.Error(err, "unable to fetch token Secret")
+		log.Error(err, "error looking up existing HEC token in Splunk")
+		result, err := r.splunkErrorResult(tokenObject, err)
+		return result, true, err
+	}
+	if !tokenValuePattern.MatchString(liveToken.Value) {
+		log.Info("existing HEC token has no usable value, issuing a new token")
+		return ctrl.Result{}, false, nil
+	}
+	if !splunkapi.TokenMatchesSpec(liveToken, r.tokenSpec(tokenObject)) ||
+		!splunkapi.TokenTagsMatch(liveToken, r.tokenTags(tokenObject)) {
+		log.Info("existing HEC token differs from SplunkToken, updating token in Splunk")
+		if _, err := r.tokenManager(tokenObject).UpdateToken(ctx, r.updatedToken(tokenObject)); err != nil {
+			log.Error(err, "error updating existing HEC token")
+			result, err := r.splunkErrorResult(tokenObject, err)
+			return result, true, err
+		}
+	}
+	log.Info("reusing existing HEC token")
+	r.Recorder.Eventf(tokenObject, corev1.EventTypeNormal, "TokenReused",
+		"Reusing HEC token %s that already exists in Splunk", tokenObject.Spec.Name)
+	issuedAt := liveToken.Details.CreatedAt
+	if issuedAt.IsZero() {
+		issuedAt = r.now()
+	}
+	// the value is still held by Splunk, so the token is not deleted if it cannot be stored
+	result, err := r.storeIssuedToken(ctx, tokenObject, liveToken.Value, metav1.NewTime(issuedAt), false)
+	return result, true, err
+}
+
+// rotateTokenSecret issues a new value for a stale HEC token without recreating the SplunkToken.
+// The old token is deleted from Splunk so that a new value is issued. Token Secrets are immutable,
+// so issueToken deletes and recreates the Secret only once the new value has been received,
+// keeping the window without a Secret short. If recreating the Secret fails, the rotation
+// is retried by the next reconcile because the token's issue time has not been updated.
+func (r *SplunkTokenReconciler) rotateTokenSecret(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	if r.missingRequiredIndex(ctx, tokenObject) {
+		return ctrl.Result{}, nil
+	}
+	rotatedAt := metav1.NewTime(r.now())
+	var valueHash string
+	if r.SplunkConfig.PreviousTokensLimit > 0 {
+		staleToken, err := r.tokenManager(tokenObject).GetToken(ctx, tokenObject.Spec.Name)
+		if err != nil && !splunkapi.IsNotFound(err) {
+			log.Error(err, "error reading stale HEC token from Splunk")
+			return r.splunkErrorResult(tokenObject, err)
+		}
+		if err == nil {
+			// stored before deleting so that an interrupted deletion is retried after a restart
+			valueHash = tokenValueHash(staleToken.Value)
+			r.recordPreviousToken(tokenObject, rotatedAt, valueHash)
+			if err := r.Status().Update(ctx, tokenObject); err != nil {
+				log.Error(err, "error recording rotated HEC token")
+				return ctrl.Result{}, err
+			}
+		}
+	}
+	if err := r.tokenManager(tokenObject).DeleteToken(ctx, tokenObject.Spec.Name); err != nil {
+		log.Error(err, "error deleting stale HEC token from Splunk")
+		return r.splunkErrorResult(tokenObject, err)
+	}
+	if valueHash != "" {
+		markPreviousTokenDeleted(tokenObject, valueHash)
+	}
+	// stored with the new token's issue time once its Secret has been created
+	tokenObject.Status.LastRotationTime = &rotatedAt
+	result, err := r.issueToken(ctx, tokenObject)
+	if err == nil && result.IsZero() {
+		r.Summary.Record(OutcomeRotated)
+		r.recordAudit(ctx, audit.ActionRotate, tokenObject)
+	}
+	return result, err
+}
+
+// issueToken creates the HEC token on the Splunk server and stores its value
+// in the SplunkToken's Secret, replacing any existing Secret.
+func (r *SplunkTokenReconciler) issueToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	tokenOptions := splunkapi.HECToken{
+		Spec:     r.tokenSpec(tokenObject),
+		Metadata: tokenMetadataFromAnnotations(tokenObject),
+		Tags:     r.tokenTags(tokenObject),
+	}
+	if r.missingRequiredIndex(ctx, tokenObject) {
+		return ctrl.Result{}, nil
+	}
+	if !hasIndexes(tokenObject) && r.SplunkConfig.FallbackIndex != "" {
+		log.Info("SplunkToken has no indexes, using fallback index", "index", r.SplunkConfig.FallbackIndex)
+	}
+	if near, count := r.Quota.nearLimit(); near && r.tokenManager(tokenObject) == r.SplunkApi {
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeWarning, "TokenQuotaNearlyReached",
+			"Splunk instance has %d of %d HEC tokens allowed", count, r.Quota.Quota)
+	}
+	hecToken, err := r.tokenManager(tokenObject).CreateToken(ctx, tokenOptions)
+	if err != nil {
+		log.Error(err, "error creating HEC token")
+		return r.splunkErrorResult(tokenObject, err)
+	}
+	if !tokenValuePattern.MatchString(hecToken.Value) {
+		metrics.InvalidTokenValues.Inc()
+		err := fmt.Errorf("invalid value returned for HEC token %s", tokenObject.Spec.Name)
+		log.Error(err, "refusing to store HEC token", "valueLength", len(hecToken.Value))
 		return ctrl.Result{}, err
 	}
+	return r.storeIssuedToken(ctx, tokenObject, hecToken.Value, metav1.NewTime(r.now()), r.SplunkConfig.DeleteTokenOnStoreFailure)
+}
+
+// missingRequiredIndex reports whether RequireIndex forbids creating the SplunkToken's HEC token
+// because it has no indexes and there is no FallbackIndex, recording an event if so.
+// Splunk may allow a token without indexes to write to every index. It is checked before an
+// existing HEC token is deleted, so the token is not revoked without a replacement.
+func (r *SplunkTokenReconciler) missingRequiredIndex(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) bool {
+	if hasIndexes(tokenObject) || r.SplunkConfig.FallbackIndex != "" || !r.SplunkConfig.RequireIndex {
+		return false
+	}
+	logf.FromContext(ctx).Info("SplunkToken has no indexes, not creating HEC token")
+	r.Recorder.Event(tokenObject, corev1.EventTypeWarning, "NoIndexConfigured",
+		"SplunkToken must set defaultIndex or allowedIndexes")
+	return true
+}
+
+// hasIndexes reports whether the SplunkToken sets a default or allowed index.
+func hasIndexes(tokenObject *stv1alpha1.SplunkToken) bool {
+	return tokenObject.Spec.DefaultIndex != "" || len(tokenObject.Spec.AllowedIndexes) > 0
+}
+
+// storeIssuedToken stores the value of a HEC token issued at issuedAt in the SplunkToken's Secret
+// and status. If deleteOnFailure is set and the value cannot be stored, the HEC token is deleted.
+func (r *SplunkTokenReconciler) storeIssuedToken(ctx context.Context, tokenObject *stv1alpha1.SplunkToken,
+	tokenValue string, issuedAt metav1.Time, deleteOnFailure bool) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// the issue time is stored in the Secret's last-rotated annotation, and in status once the Secret exists
+	tokenObject.Status.TokenIssuedAt = &issuedAt
+	if err := r.secretBackend().StoreToken(ctx, tokenObject, tokenValue); err != nil {
+		log.Error(err, "error storing HEC token")
+		if deleteOnFailure {
+			// the token value is lost, so do not leave the token behind in Splunk
+			if deleteErr := r.tokenManager(tokenObject).DeleteToken(ctx, tokenObject.Spec.Name); deleteErr != nil {
+				log.Error(deleteErr, "error deleting HEC token that could not be stored")
+			}
+		}
+		return ctrl.Result{}, err
+	}
+	tokenObject.Status.Disabled = tokenObject.Spec.Disabled
+	setTargetStatus(tokenObject, r.SplunkConfig)
+	if err := r.Status().Update(ctx, tokenObject); err != nil {
+		log.Error(err, "error updating SplunkToken status")
+		return ctrl.Result{}, err
+	}
+	if err := r.publishTokenMetadata(ctx, tokenObject); err != nil {
+		log.Error(err, "error publishing token metadata")
+		return ctrl.Result{}, err
+	}
+	r.Summary.Record(OutcomeCreated)
+	r.recordAudit(ctx, audit.ActionCreate, tokenObject)
 	return ctrl.Result{}, nil
 }
 
+// tokenSpec returns the spec the SplunkToken's HEC token is created with.
+// SplunkTokens without indexes are given the FallbackIndex, if configured.
+// The Splunk instance selects the client and is not sent to Splunk.
+// Index names are normalized so that pasted whitespace or case does not cause drift.
+func (r *SplunkTokenReconciler) tokenSpec(tokenObject *stv1alpha1.SplunkToken) stv1alpha1.SplunkTokenSpec {
+	spec := tokenObject.Spec
+	spec.SplunkInstance = ""
+	if spec.DefaultIndex == "" && len(spec.AllowedIndexes) == 0 {
+		spec.DefaultIndex = r.SplunkConfig.FallbackIndex
+	}
+	spec.DefaultIndex = r.normalizeIndex(spec.DefaultIndex)
+	if spec.AllowedIndexes != nil {
+		allowed := make([]string, 0, len(spec.AllowedIndexes))
+		for _, index := range spec.AllowedIndexes {
+			index = r.normalizeIndex(index)
+			if index != "" && !slices.Contains(allowed, index) {
+				allowed = append(allowed, index)
+			}
+		}
+		spec.AllowedIndexes = allowed
+	}
+	return spec
+}
+
+// normalizeIndex trims the index name and lowercases it if LowercaseIndexes is set.
+func (r *SplunkTokenReconciler) normalizeIndex(index string) string {
+	index = strings.TrimSpace(index)
+	if r.SplunkConfig.LowercaseIndexes {
+		index = strings.ToLower(index)
+	}
+	return index
+}
+
+// recordAudit writes an audit record of the action on the SplunkToken's HEC token.
+// Failing to write the record is logged but does not fail the reconcile.
+func (r *SplunkTokenReconciler) recordAudit(ctx context.Context, action audit.Action, tokenObject *stv1alpha1.SplunkToken) {
+	if err := r.Audit.Record(action, tokenObject.Namespace, tokenObject.Name, tokenObject.Spec.Name); err != nil {
+		logf.FromContext(ctx).Error(err, "error writing audit record", "action", action)
+	}
+}
+
+// deleteTokenWithRetry deletes the HEC token, retrying transient failures with exponential
+// backoff up to DeleteRetries times so finalization can succeed within a single reconcile.
+// Each retry spends one of the reconcile's RetryBudget. Waiting for a retry ends early when the
+// reconcile's context is done, e.g. at ReconcileTimeout or shutdown, since the token lock is held. A SplunkToken without a token name
+// never had a HEC token, and deleting the empty name would address every token, so nothing is deleted.
+func (r *SplunkTokenReconciler) deleteTokenWithRetry(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) error {
+	if !hasTokenName(tokenObject) {
+		return nil
+	}
+	backoff := wait.Backoff{
+		Steps:    r.SplunkConfig.DeleteRetries + 1,
+		Duration: r.SplunkConfig.DeleteRetryBackoff,
+		Factor:   2,
+	}
+	if backoff.Duration <= 0 {
+		backoff.Duration = defaultDeleteRetryBackoff
+	}
+	attempts := 0
+	var err error
+	waitErr := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		attempts++
+		err = r.tokenManager(tokenObject).DeleteToken(ctx, tokenObject.Spec.Name)
+		// stop retrying once the reconcile has run out of time or retries
+		retriable := err != nil && ctx.Err() == nil && isRetriableSplunkError(err) &&
+			attempts <= r.SplunkConfig.DeleteRetries && splunkapi.SpendRetry(ctx)
+		return !retriable, nil
+	})
+	if err == nil && waitErr != nil {
+		// the context was done before the first attempt
+		return waitErr
+	}
+	if err != nil && attempts > 1 {
+		return fmt.Errorf("deleting HEC token failed after %d attempts: %w", attempts, err)
+	}
+	return err
+}
+
+// namespaceManaged reports whether SplunkTokens in the namespace are reconciled: it must be in
+// AllowedNamespaces, unless that is empty, and must not be in DeniedNamespaces.
+func namespaceManaged(splunkConfig config.General, namespace string) bool {
+	if len(splunkConfig.AllowedNamespaces) > 0 && !slices.Contains(splunkConfig.AllowedNamespaces, namespace) {
+		return false
+	}
+	return !slices.Contains(splunkConfig.DeniedNamespaces, namespace)
+}
+
+// allowDuplicateToken applies the DuplicateTokenPolicy to a SplunkToken that is not the canonical
+// TokenObjectName of its namespace, recording the decision as an event. It reports whether
+// the SplunkToken should be reconciled.
+func (r *SplunkTokenReconciler) allowDuplicateToken(tokenObject *stv1alpha1.SplunkToken) bool {
+	policy := r.SplunkConfig.DuplicateTokenPolicy
+	if policy == "" || tokenObject.Name == config.TokenObjectName {
+		return true
+	}
+	switch policy {
+	case config.DuplicateTokenPolicyAdopt:
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeNormal, "DuplicateSplunkToken",
+			"managing SplunkToken in addition to %s", config.TokenObjectName)
+		return true
+	case config.DuplicateTokenPolicyIgnore:
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeNormal, "DuplicateSplunkToken",
+			"ignoring SplunkToken, only %s is managed", config.TokenObjectName)
+	default:
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeWarning, "DuplicateSplunkToken",
+			"SplunkToken is not managed, only one SplunkToken named %s is allowed per namespace", config.TokenObjectName)
+	}
+	return false
+}
+
+// updateTokenObject applies mutate to the SplunkToken and updates it. If the update conflicts
+// with a concurrent change, the SplunkToken is fetched again and mutate is reapplied to the
+// latest version, up to UpdateConflictRetries times.
+func (r *SplunkTokenReconciler) updateTokenObject(ctx context.Context, tokenObject *stv1alpha1.SplunkToken,
+	mutate func(*stv1alpha1.SplunkToken)) error {
+	backoff := retry.DefaultRetry
+	backoff.Steps = r.SplunkConfig.UpdateConflictRetries + 1
+	attempts := 0
+	return retry.RetryOnConflict(backoff, func() error {
+		if attempts++; attempts > 1 {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(tokenObject), tokenObject); err != nil {
+				return err
+			}
+		}
+		mutate(tokenObject)
+		return r.Update(ctx, tokenObject)
+	})
+}
+
+// hasLegacyFinalizer reports whether the SplunkToken has a finalizer set by an earlier
+// version of the operator.
+func (r *SplunkTokenReconciler) hasLegacyFinalizer(tokenObject *stv1alpha1.SplunkToken) bool {
+	return slices.ContainsFunc(r.SplunkConfig.LegacyFinalizers, func(legacyFinalizer string) bool {
+		return controllerutil.ContainsFinalizer(tokenObject, legacyFinalizer)
+	})
+}
+
+// migrateFinalizers replaces the SplunkToken's legacy finalizers with TokenFinalizer.
+func (r *SplunkTokenReconciler) migrateFinalizers(tokenObject *stv1alpha1.SplunkToken) {
+	for _, legacyFinalizer := range r.SplunkConfig.LegacyFinalizers {
+		if controllerutil.RemoveFinalizer(tokenObject, legacyFinalizer) {
+			controllerutil.AddFinalizer(tokenObject, config.TokenFinalizer)
+		}
+	}
+}
+
+// finalizerTimedOut reports whether the SplunkToken has been deleting for longer than FinalizerTimeout.
+func (r *SplunkTokenReconciler) finalizerTimedOut(tokenObject *stv1alpha1.SplunkToken) bool {
+	if r.SplunkConfig.FinalizerTimeout <= 0 {
+		return false
+	}
+	return r.now().Sub(tokenObject.DeletionTimestamp.Time) > r.SplunkConfig.FinalizerTimeout
+}
+
+// isRetriableSplunkError reports whether a failed Splunk request may succeed if retried.
+// Permission errors will not resolve themselves and are never retried.
+func isRetriableSplunkError(err error) bool {
+	return !splunkapi.IsForbidden(err) && !splunkapi.IsMaintenance(err)
+}
+
+// splunkErrorResult decides how to retry after a failed Splunk request.
+// Maintenance will end on its own, so it is reported with an event and retried after a long delay.
+// A 403 Forbidden will not resolve itself until the authentication token's permissions
+// are fixed, so it is reported with an event and retried slowly instead of with backoff.
+func (r *SplunkTokenReconciler) splunkErrorResult(tokenObject *stv1alpha1.SplunkToken, err error) (ctrl.Result, error) {
+	if splunkapi.IsMaintenance(err) {
+		r.Recorder.Eventf(tokenObject, corev1.EventTypeNormal, "SplunkMaintenance",
+			"Splunk is under maintenance, postponing token operations: %v", err)
+		return ctrl.Result{RequeueAfter: r.maintenanceRequeueInterval()}, nil
+	}
+	if !splunkapi.IsForbidden(err) {
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(tokenObject, corev1.EventTypeWarning, "PermissionDenied",
+		"Splunk rejected the request, check the permissions of the Splunk authentication token: %v", err)
+	return ctrl.Result{RequeueAfter: r.forbiddenRequeueInterval()}, nil
+}
+
+func (r *SplunkTokenReconciler) maintenanceRequeueInterval() time.Duration {
+	if r.SplunkConfig.MaintenanceRequeueInterval > 0 {
+		return r.SplunkConfig.MaintenanceRequeueInterval
+	}
+	return defaultMaintenanceRequeueInterval
+}
+
+// maintenanceWindow returns the configured maintenance window in progress, if any.
+func (r *SplunkTokenReconciler) maintenanceWindow() (config.MaintenanceWindow, bool) {
+	now := r.now()
+	for _, window := range r.SplunkConfig.MaintenanceWindows {
+		if window.Contains(now) {
+			return window, true
+		}
+	}
+	return config.MaintenanceWindow{}, false
+}
+
+func (r *SplunkTokenReconciler) forbiddenRequeueInterval() time.Duration {
+	if r.SplunkConfig.ForbiddenRequeueInterval > 0 {
+		return r.SplunkConfig.ForbiddenRequeueInterval
+	}
+	return defaultForbiddenRequeueInterval
+}
+
+func (r *SplunkTokenReconciler) terminatingSecretRequeueInterval() time.Duration {
+	if r.SplunkConfig.TerminatingSecretRequeueInterval > 0 {
+		return r.SplunkConfig.TerminatingSecretRequeueInterval
+	}
+	return defaultTerminatingSecretRequeueInterval
+}
+
+// deleteTokenSecret deletes the SplunkToken's Secret if it exists and is managed by the operator.
+func (r *SplunkTokenReconciler) deleteTokenSecret(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) error {
+	var tokenSecret corev1.Secret
+	secretKey := types.NamespacedName{Namespace: tokenObject.Namespace, Name: tokenSecretName(r.SplunkConfig, tokenObject)}
+	if err := r.Get(ctx, secretKey, &tokenSecret); errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isManagedSecret(&tokenSecret, tokenObject) {
+		logf.FromContext(ctx).Info("token Secret is not managed by the operator, leaving it in place")
+		return nil
+	}
+	if issuedForPreviousToken(&tokenSecret, tokenObject) {
+		// the Secret already holds the token of another SplunkToken, e.g. one recreated to rotate this one
+		logf.FromContext(ctx).Info("token Secret was issued for another SplunkToken, leaving it in place")
+		return nil
+	}
+	err := r.Delete(ctx, &tokenSecret)
+	if errors.IsNotFound(err) {
+		err = nil
+	}
+	metrics.RecordSecretOperation("delete", err)
+	return err
+}
+
+// keepsSecretForRotation reports whether finalizing the SplunkToken leaves its Secret to the
+// SecretLifecycle. With SecretLifecycleFinalizer, which the garbage collector does not clean up
+// after, the Secret is only kept for a SplunkToken deleted with the AllowDeleteAnnotation to rotate
+// its token, so the namespace is not left without a Secret until the recreated SplunkToken replaces it.
+func (r *SplunkTokenReconciler) keepsSecretForRotation(tokenObject *stv1alpha1.SplunkToken) bool {
+	return r.SplunkConfig.SecretLifecycle != config.SecretLifecycleFinalizer ||
+		tokenObject.Annotations[config.AllowDeleteAnnotation] == "true"
+}
+
+// reconcileExistingSecret replaces the Secret stored on the server with wantSecret if their data differs.
+func (r *SplunkTokenReconciler) reconcileExistingSecret(ctx context.Context, tokenObject *stv1alpha1.SplunkToken, wantSecret *corev1.Secret) error {
+	var existingSecret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKeyFromObject(wantSecret), &existingSecret); err != nil {
+		return err
+	}
+	if !isManagedSecret(&existingSecret, tokenObject) {
+		return fmt.Errorf("secret %s exists but is not managed by the operator", existingSecret.Name)
+	}
+	if maps.EqualFunc(existingSecret.Data, wantSecret.Data, bytes.Equal) {
+		return nil
+	}
+	return r.updateSecret(ctx, &existingSecret, wantSecret)
+}
+
+// updateSecret applies newSecret to the existing token Secret. With MutableSecrets a mutable
+// Secret of the same type is patched in place, along with the labels and annotations the operator
+// sets. Otherwise the Secret is replaced.
+func (r *SplunkTokenReconciler) updateSecret(ctx context.Context, oldSecret, newSecret *corev1.Secret) error {
+	if !r.SplunkConfig.MutableSecrets || ptr.Deref(oldSecret.Immutable, false) || oldSecret.Type != newSecret.Type {
+		return r.replaceSecret(ctx, oldSecret, newSecret)
+	}
+	patch := client.MergeFrom(oldSecret.DeepCopy())
+	oldSecret.Data = newSecret.Data
+	mergeSecretMetadata(oldSecret, newSecret)
+	err := r.Patch(ctx, oldSecret, patch)
+	metrics.RecordSecretOperation("update", err)
+	return err
+}
+
+// patchSecretMetadata sets the labels and annotations of wantSecret on the existing token Secret,
+// which Kubernetes allows even when the Secret is immutable.
+func (r *SplunkTokenReconciler) patchSecretMetadata(ctx context.Context, secret, wantSecret *corev1.Secret) error {
+	patch := client.MergeFrom(secret.DeepCopy())
+	mergeSecretMetadata(secret, wantSecret)
+	err := r.Patch(ctx, secret, patch)
+	metrics.RecordSecretOperation("update", err)
+	return err
+}
+
+// secretMetadataCurrent reports whether the Secret has every label and annotation of wantSecret.
+// Labels and annotations set by others are ignored.
+func secretMetadataCurrent(secret, wantSecret *corev1.Secret) bool {
+	for key, value := range wantSecret.Labels {
+		if current, found := secret.Labels[key]; !found || current != value {
+			return false
+		}
+	}
+	for key, value := range wantSecret.Annotations {
+		if current, found := secret.Annotations[key]; !found || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeSecretMetadata(secret, wantSecret *corev1.Secret) {
+	for key, value := range wantSecret.Labels {
+		metav1.SetMetaDataLabel(&secret.ObjectMeta, key, value)
+	}
+	for key, value := range wantSecret.Annotations {
+		metav1.SetMetaDataAnnotation(&secret.ObjectMeta, key, value)
+	}
+}
+
+// replaceSecret swaps the existing token Secret for a new one.
+// Token Secrets are immutable, so the old Secret is deleted before the new one is created;
+// the deletion is counted as a delete and the creation of its replacement as an update.
+func (r *SplunkTokenReconciler) replaceSecret(ctx context.Context, oldSecret, newSecret *corev1.Secret) error {
+	err := r.Delete(ctx, oldSecret)
+	if errors.IsNotFound(err) {
+		err = nil
+	}
+	metrics.RecordSecretOperation("delete", err)
+	if err != nil {
+		return err
+	}
+	err = r.Create(ctx, newSecret)
+	metrics.RecordSecretOperation("update", err)
+	return err
+}
+
 // SetupWithManager sets up the controller with the Manager.
+// With ConcurrentDeletions, SplunkTokens being deleted are finalized by a separate controller
+// with ConcurrentDeletions workers of its own, so a backlog of deletions neither waits for
+// nor holds up the single worker reconciling the other SplunkTokens. With the finalizer
+// SecretLifecycle, token Secrets have no owner reference and are mapped to their SplunkTokens.
 func (r *SplunkTokenReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&stv1alpha1.SplunkToken{}).
+	tokens := builder.WithPredicates()
+	if r.SplunkConfig.ConcurrentDeletions > 0 {
+		err := ctrl.NewControllerManagedBy(mgr).
+			For(&stv1alpha1.SplunkToken{}, builder.WithPredicates(predicate.NewPredicateFuncs(isFinalizing))).
+			Named("splunktoken-finalizer").
+			WithOptions(controller.Options{MaxConcurrentReconciles: r.SplunkConfig.ConcurrentDeletions}).
+			Complete(r)
+		if err != nil {
+			return err
+		}
+		tokens = builder.WithPredicates(predicate.Not(predicate.NewPredicateFuncs(isFinalizing)))
+	}
+	tokenController := ctrl.NewControllerManagedBy(mgr).
+		For(&stv1alpha1.SplunkToken{}, tokens).
 		Named("splunktoken").
-		Owns(&corev1.Secret{}).
-		Complete(r)
+		Owns(&corev1.Secret{})
+	if r.SplunkConfig.SecretLifecycle == config.SecretLifecycleFinalizer {
+		tokenController = tokenController.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.tokensForSecret))
+	}
+	return tokenController.Complete(r)
+}
+
+// isFinalizing reports whether the object is being deleted.
+func isFinalizing(obj client.Object) bool {
+	return !obj.GetDeletionTimestamp().IsZero()
+}
+
+// renameTokenSecret moves the token value from a managed Secret with a previously configured
+// name to a Secret with the current name, so changing the Secret name does not issue a new token.
+// It reports whether a Secret was renamed.
+func (r *SplunkTokenReconciler) renameTokenSecret(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (bool, error) {
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(tokenObject.Namespace)); err != nil {
+		return false, err
+	}
+	for _, oldSecret := range secrets.Items {
+		if oldSecret.Name == tokenSecretName(r.SplunkConfig, tokenObject) || !isManagedSecret(&oldSecret, tokenObject) ||
+			ownedByOtherToken(&oldSecret, tokenObject) {
+			continue
+		}
+		tokenValue, found := tokenValueFromSecret(&oldSecret)
+		if !found {
+			continue
+		}
+		var newSecret corev1.Secret
+		r.newSecretObject(tokenObject, tokenValue, &newSecret)
+		if err := r.setSecretOwner(tokenObject, &newSecret); err != nil {
+			return false, err
+		}
+		err := r.Create(ctx, &newSecret)
+		metrics.RecordSecretOperation("create", err)
+		if err != nil {
+			return false, err
+		}
+		err = r.Delete(ctx, &oldSecret)
+		if errors.IsNotFound(err) {
+			err = nil
+		}
+		metrics.RecordSecretOperation("delete", err)
+		return true, err
+	}
+	return false, nil
+}
+
+// withinNamespaceLimit reports whether the SplunkToken is among the oldest
+// MaxTokensPerNamespace tokens in its namespace and may have a HEC token created.
+func (r *SplunkTokenReconciler) withinNamespaceLimit(ctx context.Context, tokenObject *stv1alpha1.SplunkToken) (bool, error) {
+	limit := r.SplunkConfig.MaxTokensPerNamespace
+	if limit <= 0 {
+		return true, nil
+	}
+	var tokens stv1alpha1.SplunkTokenList
+	if err := r.List(ctx, &tokens, client.InNamespace(tokenObject.Namespace)); err != nil {
+		return false, err
+	}
+	if len(tokens.Items) <= limit {
+		return true, nil
+	}
+	slices.SortFunc(tokens.Items, func(a, b stv1alpha1.SplunkToken) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	position := slices.IndexFunc(tokens.Items, func(t stv1alpha1.SplunkToken) bool {
+		return t.UID == tokenObject.UID && t.Name == tokenObject.Name
+	})
+	return position >= 0 && position < limit, nil
 }
 
-func (r *SplunkTokenReconciler) newSecretObject(namespace, tokenValue string, secret *corev1.Secret) {
-	secret.Name = config.OwnedObjectName
-	secret.Namespace = namespace
-	outputsConf := `[httpout]
-httpEventCollectorToken = %s
-uri = %s`
-	data := fmt.Appendf([]byte{}, outputsConf, tokenValue, r.collectorUri())
+func (r *SplunkTokenReconciler) newSecretObject(tokenObject *stv1alpha1.SplunkToken, tokenValue string, secret *corev1.Secret) {
+	secret.Name = tokenSecretName(r.SplunkConfig, tokenObject)
+	secret.Namespace = tokenObject.Namespace
+	secret.Type = corev1.SecretType(r.SplunkConfig.SecretType)
+	secret.Labels = maps.Clone(r.SplunkConfig.SecretLabels)
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	if r.SplunkConfig.SecretClusterIDLabel != "" {
+		secret.Labels[r.SplunkConfig.SecretClusterIDLabel] = tokenObject.Spec.Name
+	}
+	secret.Labels[config.ManagedSecretLabel] = "true"
+	secret.Annotations = maps.Clone(r.SplunkConfig.SecretAnnotations)
+	if issuedAt := tokenObject.Status.TokenIssuedAt; issuedAt != nil {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[config.LastRotatedAnnotation] = issuedAt.UTC().Format(time.RFC3339)
+	}
+	hecToken := splunkapi.HECToken{Spec: tokenObject.Spec, Value: tokenValue}
 	secret.Data = map[string][]byte{
-		config.SecretDataKey: data,
+		r.secretDataKey(): BuildOutputsConf(hecToken, r.SplunkConfig),
+	}
+	if r.SplunkConfig.SecretHECURL {
+		secret.Data[config.HECURLDataKey] = []byte(collectorURI(tokenObject.Spec.SplunkInstance, r.SplunkConfig))
+	}
+	if r.SplunkConfig.SecretIndexRouting {
+		secret.Data[config.IndexRoutingDataKey] = BuildIndexRouting(r.tokenSpec(tokenObject))
+	}
+	secret.Immutable = ptr.To(!r.SplunkConfig.MutableSecrets)
+}
+
+func (r *SplunkTokenReconciler) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock.Now()
+	}
+	return time.Now()
+}
+
+// tokenSecretName returns the name of the SplunkToken's Secret. A SplunkToken adopted by
+// DuplicateTokenPolicyAdopt has its name appended to the configured name, so it does not
+// share the Secret of the canonical SplunkToken.
+func tokenSecretName(splunkConfig config.General, tokenObject *stv1alpha1.SplunkToken) string {
+	name := config.OwnedObjectName
+	if splunkConfig.SecretName != "" {
+		name = splunkConfig.SecretName
+	}
+	if splunkConfig.DuplicateTokenPolicy == config.DuplicateTokenPolicyAdopt && tokenObject.Name != config.TokenObjectName {
+		name += "-" + tokenObject.Name
 	}
-	truePtr := true
-	secret.Immutable = &truePtr
+	return name
 }
 
-func (r *SplunkTokenReconciler) collectorUri() string {
-	return fmt.Sprintf("https://http-inputs-%s.splunkcloud.com:443", r.SplunkConfig.SplunkInstance)
+// rotationDeadline returns when the SplunkToken's HEC token becomes stale.
+// The skew tolerance keeps rotation from firing early if the operator's clock runs ahead.
+func rotationDeadline(splunkConfig config.General, tokenObject *stv1alpha1.SplunkToken) time.Time {
+	maxAge, _ := tokenMaxAge(splunkConfig, tokenObject)
+	return tokenAgeStart(splunkConfig, tokenObject).Add(maxAge + splunkConfig.RotationSkewTolerance)
+}
+
+// tokenAgeStart returns the time the age of the SplunkToken's HEC token is measured from.
+// With the secret rotation strategy that is when its current value was issued.
+func tokenAgeStart(splunkConfig config.General, tokenObject *stv1alpha1.SplunkToken) time.Time {
+	if splunkConfig.RotationStrategy == config.RotationStrategySecret && tokenObject.Status.TokenIssuedAt != nil {
+		return tokenObject.Status.TokenIssuedAt.Time
+	}
+	return tokenObject.CreationTimestamp.Time
+}
+
+// rotationWait returns how long rotation of the SplunkToken's HEC token must be postponed
+// to honor MinRotationInterval. A SplunkToken that was never rotated in place was last
+// rotated when it was created.
+func (r *SplunkTokenReconciler) rotationWait(tokenObject *stv1alpha1.SplunkToken, now time.Time) time.Duration {
+	if r.SplunkConfig.MinRotationInterval <= 0 {
+		return 0
+	}
+	lastRotation := tokenObject.CreationTimestamp
+	if tokenObject.Status.LastRotationTime != nil {
+		lastRotation = *tokenObject.Status.LastRotationTime
+	}
+	return lastRotation.Add(r.SplunkConfig.MinRotationInterval).Sub(now)
+}
+
+// tokenMaxAge returns the max age of the SplunkToken's HEC token, which the MaxAgeAnnotation
+// overrides. If the annotation is invalid, TokenMaxAge is returned along with the parse error.
+func tokenMaxAge(splunkConfig config.General, tokenObject *stv1alpha1.SplunkToken) (time.Duration, error) {
+	value, found := tokenObject.Annotations[config.MaxAgeAnnotation]
+	if !found {
+		return splunkConfig.TokenMaxAge, nil
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil {
+		return splunkConfig.TokenMaxAge, err
+	}
+	if maxAge <= 0 {
+		return splunkConfig.TokenMaxAge, fmt.Errorf("max age %s is not positive", value)
+	}
+	return maxAge, nil
+}
+
+func (r *SplunkTokenReconciler) secretDataKey() string {
+	if r.SplunkConfig.SecretDataKey != "" {
+		return r.SplunkConfig.SecretDataKey
+	}
+	return config.SecretDataKey
+}
+
+// tokenMetadataFromAnnotations collects the HEC token metadata set through
+// annotations with the TokenMetadataAnnotationPrefix.
+func tokenMetadataFromAnnotations(tokenObject *stv1alpha1.SplunkToken) map[string]string {
+	var metadata map[string]string
+	for key, value := range tokenObject.Annotations {
+		field, found := strings.CutPrefix(key, config.TokenMetadataAnnotationPrefix)
+		if !found || field == "" {
+			continue
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[field] = value
+	}
+	return metadata
+}
+
+// hasTokenName reports whether the SplunkToken names its HEC token. A name that is present but
+// empty or blank is treated the same as a missing name.
+func hasTokenName(tokenObject *stv1alpha1.SplunkToken) bool {
+	return strings.TrimSpace(tokenObject.Spec.Name) != ""
+}
+
+// tokenTags returns the configured TokenTags of the SplunkToken's HEC token, with the tags
+// mapped from the SplunkToken's labels by TokenTagLabels. Labels that are not set are skipped.
+func (r *SplunkTokenReconciler) tokenTags(tokenObject *stv1alpha1.SplunkToken) map[string]string {
+	var tags map[string]string
+	if len(r.SplunkConfig.TokenTags) > 0 {
+		tags = maps.Clone(r.SplunkConfig.TokenTags)
+	}
+	for tag, label := range r.SplunkConfig.TokenTagLabels {
+		value, found := tokenObject.Labels[label]
+		if !found {
+			continue
+		}
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		tags[tag] = value
+	}
+	return tags
+}
+
+// updatedToken returns the HEC token to send when updating the SplunkToken's token in Splunk.
+func (r *SplunkTokenReconciler) updatedToken(tokenObject *stv1alpha1.SplunkToken) splunkapi.HECToken {
+	return splunkapi.HECToken{Spec: r.tokenSpec(tokenObject), Tags: r.tokenTags(tokenObject)}
+}
+
+// isManagedSecret reports whether the operator may modify the Secret. Secrets created by earlier
+// versions of the operator lack the managed label, so Secrets controlled by the SplunkToken are also managed.
+func isManagedSecret(secret *corev1.Secret, tokenObject *stv1alpha1.SplunkToken) bool {
+	return secret.Labels[config.ManagedSecretLabel] == "true" || metav1.IsControlledBy(secret, tokenObject)
+}
+
+// tokenValueFromSecret reads the HEC token value out of an existing Secret
+// regardless of which data key it was stored under.
+func tokenValueFromSecret(secret *corev1.Secret) (string, bool) {
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		if match := tokenLinePattern.FindSubmatch(secret.Data[key]); match != nil {
+			return string(match[1]), true
+		}
+	}
+	return "", false
+}
+
+// tokenManager returns the client of the Splunk instance holding the SplunkToken's HEC token,
+// or nil if the SplunkToken selects an instance without a client.
+func (r *SplunkTokenReconciler) tokenManager(tokenObject *stv1alpha1.SplunkToken) splunkapi.TokenManager {
+	instance := tokenObject.Spec.SplunkInstance
+	if instance == "" || instance == r.SplunkConfig.SplunkInstance {
+		return r.SplunkApi
+	}
+	return r.SplunkInstances[instance]
 }
@@ -17,14 +17,23 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"maps"
+	"reflect"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,16 +42,26 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
 	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/audit"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
 	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
 )
 
+const testTokenValue = "0b6f1a9e-3c4d-4e5f-8a7b-9c0d1e2f3a4b"
+
 var request = reconcile.Request{
 	NamespacedName: types.NamespacedName{
 		Namespace: "test-namespace",
@@ -81,6 +100,7 @@ func TestReconcile(t *testing.T) {
 
 		fakeClient := fakeclient.NewClientBuilder().
 			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
 			WithRuntimeObjects(&splunkToken).
 			Build()
 
@@ -115,6 +135,7 @@ func TestReconcile(t *testing.T) {
 
 		fakeClient := fakeclient.NewClientBuilder().
 			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
 			WithRuntimeObjects(&splunkToken).
 			Build()
 
@@ -145,6 +166,46 @@ func TestReconcile(t *testing.T) {
 		if resultToken.DeletionTimestamp.IsZero() {
 			t.Error("SplunkToken object should have DeletionTimestamp")
 		}
+		if resultToken.Annotations[config.AllowDeleteAnnotation] != "true" {
+			t.Errorf("SplunkToken object should have annotation %s=true", config.AllowDeleteAnnotation)
+		}
+	})
+
+	t.Run("does not rotate SplunkToken object if TokenMaxAge is zero", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.CreationTimestamp = metav1.NewTime(time.Now().Add(-3 * time.Hour))
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		var resultToken stv1alpha1.SplunkToken
+		err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken)
+		if err != nil {
+			t.Errorf("error checking updated token: %s", err)
+		}
+
+		if !resultToken.DeletionTimestamp.IsZero() {
+			t.Error("SplunkToken object should not have DeletionTimestamp")
+		}
 	})
 
 	t.Run("creates new token if Secret does not exist", func(t *testing.T) {
@@ -152,6 +213,7 @@ func TestReconcile(t *testing.T) {
 
 		fakeClient := fakeclient.NewClientBuilder().
 			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
 			WithRuntimeObjects(&splunkToken).
 			Build()
 
@@ -194,9 +256,9 @@ func TestReconcile(t *testing.T) {
 		// base64 encoding of this outputs.conf:
 		//
 		//     [httpout]
-		//     httpEventCollectorToken = <guid-value>
+		//     httpEventCollectorToken = 0b6f1a9e-3c4d-4e5f-8a7b-9c0d1e2f3a4b
 		//     uri = https://http-inputs-<splunk-collector-uri>.splunkcloud.com:443
-		wantStr := "W2h0dHBvdXRdCmh0dHBFdmVudENvbGxlY3RvclRva2VuID0gPGd1aWQtdmFsdWU+CnVyaSA9IGh0dHBzOi8vaHR0cC1pbnB1dHMtPHNwbHVuay1jb2xsZWN0b3ItdXJpPi5zcGx1bmtjbG91ZC5jb206NDQz"
+		wantStr := "W2h0dHBvdXRdCmh0dHBFdmVudENvbGxlY3RvclRva2VuID0gMGI2ZjFhOWUtM2M0ZC00ZTVmLThhN2ItOWMwZDFlMmYzYTRiCnVyaSA9IGh0dHBzOi8vaHR0cC1pbnB1dHMtPHNwbHVuay1jb2xsZWN0b3ItdXJpPi5zcGx1bmtjbG91ZC5jb206NDQz"
 		if gotData, ok := hecSecret.Data["outputs.conf"]; !ok {
 			keys := slices.Sorted(maps.Keys(hecSecret.Data))
 			t.Errorf("token not stored on correct key\nwant: %s\ngot: %v", "outputs.conf", keys)
@@ -207,72 +269,3715 @@ func TestReconcile(t *testing.T) {
 			}
 		}
 	})
-}
 
-type errorClient struct {
-	client.Client
-	err func() *kerrors.StatusError
-}
+	t.Run("uses configured outputs.conf stanza name", func(t *testing.T) {
+		splunkToken := testSplunkToken()
 
-func (e errorClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
-	return e.err()
-}
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
 
-func objectNotFound() *kerrors.StatusError {
-	return kerrors.NewNotFound(schema.GroupResource{}, config.OwnedObjectName)
-}
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{
+				TokenMaxAge:    time.Hour,
+				SplunkInstance: "<splunk-collector-uri>",
+				OutputStanza:   "splunkcloud",
+			},
+		}
 
-type mockSplunkClient struct {
-	splunkapi.TokenManager
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
 
-	createCalled bool
-	deleteCalled bool
-	create       func() (*splunkapi.HECToken, error)
-	delete       func() error
-}
+		// base64 encoding of this outputs.conf:
+		//
+		//     [splunkcloud]
+		//     httpEventCollectorToken = 0b6f1a9e-3c4d-4e5f-8a7b-9c0d1e2f3a4b
+		//     uri = https://http-inputs-<splunk-collector-uri>.splunkcloud.com:443
+		wantStr := "W3NwbHVua2Nsb3VkXQpodHRwRXZlbnRDb2xsZWN0b3JUb2tlbiA9IDBiNmYxYTllLTNjNGQtNGU1Zi04YTdiLTljMGQxZTJmM2E0Ygp1cmkgPSBodHRwczovL2h0dHAtaW5wdXRzLTxzcGx1bmstY29sbGVjdG9yLXVyaT4uc3BsdW5rY2xvdWQuY29tOjQ0Mw=="
+		hecSecret := getTokenSecret(t, fakeClient)
+		if gotStr := base64.StdEncoding.EncodeToString(hecSecret.Data["outputs.conf"]); gotStr != wantStr {
+			t.Errorf("secret data not formatted correctly\ngot: %s\nwant: %s", gotStr, wantStr)
+		}
+	})
 
-func (m *mockSplunkClient) CreateToken(ctx context.Context, token splunkapi.HECToken) (*splunkapi.HECToken, error) {
-	m.createCalled = true
-	return m.create()
+	for name, outputSourcetype := range map[string]string{
+		"adds sourcetype to outputs.conf":                          "_json",
+		"adds sourcetype of token to outputs.conf without default": "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.Sourcetype = "osd:audit"
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+				SplunkConfig: config.General{
+					TokenMaxAge:      time.Hour,
+					SplunkInstance:   "<splunk-collector-uri>",
+					OutputSourcetype: outputSourcetype,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Errorf("unexpected error during reconcile: %s", err)
+			}
+
+			// base64 encoding of this outputs.conf:
+			//
+			//     [httpout]
+			//     httpEventCollectorToken = 0b6f1a9e-3c4d-4e5f-8a7b-9c0d1e2f3a4b
+			//     uri = https://http-inputs-<splunk-collector-uri>.splunkcloud.com:443
+			//     sourcetype = osd:audit
+			wantStr := "W2h0dHBvdXRdCmh0dHBFdmVudENvbGxlY3RvclRva2VuID0gMGI2ZjFhOWUtM2M0ZC00ZTVmLThhN2ItOWMwZDFlMmYzYTRiCnVyaSA9IGh0dHBzOi8vaHR0cC1pbnB1dHMtPHNwbHVuay1jb2xsZWN0b3ItdXJpPi5zcGx1bmtjbG91ZC5jb206NDQzCnNvdXJjZXR5cGUgPSBvc2Q6YXVkaXQ="
+			hecSecret := getTokenSecret(t, fakeClient)
+			if gotStr := base64.StdEncoding.EncodeToString(hecSecret.Data["outputs.conf"]); gotStr != wantStr {
+				t.Errorf("secret data not formatted correctly\ngot: %s\nwant: %s", gotStr, wantStr)
+			}
+		})
+	}
 }
-func (m *mockSplunkClient) DeleteToken(ctx context.Context, name string) error {
-	m.deleteCalled = true
-	return m.delete()
+
+func TestReconcileRotationBoundary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	created := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		tolerance  time.Duration
+		wantRotate bool
+	}{
+		{name: "does not rotate at exactly TokenMaxAge", now: created.Add(time.Hour), wantRotate: false},
+		{name: "rotates just after TokenMaxAge", now: created.Add(time.Hour + time.Nanosecond), wantRotate: true},
+		{name: "does not rotate within skew tolerance", now: created.Add(time.Hour + 30*time.Second), tolerance: 30 * time.Second, wantRotate: false},
+		{name: "rotates just after skew tolerance", now: created.Add(time.Hour + 30*time.Second + time.Nanosecond), tolerance: 30 * time.Second, wantRotate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.CreationTimestamp = metav1.NewTime(created)
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, RotationSkewTolerance: tt.tolerance},
+				Clock:        clocktesting.NewFakePassiveClock(tt.now),
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+
+			var resultToken stv1alpha1.SplunkToken
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken); err != nil {
+				t.Fatalf("error checking updated token: %s", err)
+			}
+			if rotated := !resultToken.DeletionTimestamp.IsZero(); rotated != tt.wantRotate {
+				t.Errorf("expected rotation %t but got %t", tt.wantRotate, rotated)
+			}
+			if !tt.wantRotate && !resultToken.Status.TokenIssuedAt.Time.Equal(tt.now) {
+				t.Errorf("expected token issued at %s but got %s", tt.now, resultToken.Status.TokenIssuedAt)
+			}
+		})
+	}
 }
 
-func createSuccess() (*splunkapi.HECToken, error) {
-	token := splunkapi.HECToken{
-		Spec: stv1alpha1.SplunkTokenSpec{
-			Name: "<internal-cluster-id>",
-		},
-		Value: "<guid-value>",
+func TestReconcileMaxAgeAnnotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	created := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantRotate  bool
+		wantEvent   bool
+	}{
+		{name: "uses TokenMaxAge without annotation", wantRotate: true},
+		{name: "uses valid max age annotation", annotations: map[string]string{config.MaxAgeAnnotation: "72h"}, wantRotate: false},
+		{name: "falls back to TokenMaxAge for invalid annotation", annotations: map[string]string{config.MaxAgeAnnotation: "three days"}, wantRotate: true, wantEvent: true},
+		{name: "falls back to TokenMaxAge for negative annotation", annotations: map[string]string{config.MaxAgeAnnotation: "-72h"}, wantRotate: true, wantEvent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.CreationTimestamp = metav1.NewTime(created)
+			splunkToken.Annotations = tt.annotations
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			recorder := record.NewFakeRecorder(1)
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				Recorder:     recorder,
+				SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+				Clock:        clocktesting.NewFakePassiveClock(created.Add(2 * time.Hour)),
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+
+			var resultToken stv1alpha1.SplunkToken
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken); err != nil {
+				t.Fatalf("error checking updated token: %s", err)
+			}
+			if rotated := !resultToken.DeletionTimestamp.IsZero(); rotated != tt.wantRotate {
+				t.Errorf("expected rotation %t but got %t", tt.wantRotate, rotated)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !tt.wantEvent || !strings.Contains(event, "InvalidMaxAge") {
+					t.Errorf("unexpected event %s", event)
+				}
+			default:
+				if tt.wantEvent {
+					t.Error("expected InvalidMaxAge event")
+				}
+			}
+		})
 	}
-	return &token, nil
 }
 
-func createErrorIfCalled() (*splunkapi.HECToken, error) {
-	return nil, errors.New("should not call CreateToken")
+func TestReconcileMinRotationInterval(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("refuses rapid successive rotations", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.CreationTimestamp = metav1.NewTime(start.Add(-2 * time.Hour))
+		issuedAt := metav1.NewTime(start.Add(-time.Minute))
+		splunkToken.Status.TokenIssuedAt = &issuedAt
+		tokenSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = 00000000-0000-0000-0000-000000000000"),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteSuccess}
+		recorder := record.NewFakeRecorder(1)
+		fakeClock := clocktesting.NewFakePassiveClock(start)
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  recorder,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				// misconfigured so every reconcile finds the token stale
+				TokenMaxAge:         time.Second,
+				RotationStrategy:    config.RotationStrategySecret,
+				MinRotationInterval: time.Hour,
+			},
+			Clock: fakeClock,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during first reconcile: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Fatal("expected first trigger to rotate the token")
+		}
+		var rotatedToken stv1alpha1.SplunkToken
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &rotatedToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if rotatedToken.Status.LastRotationTime == nil || !rotatedToken.Status.LastRotationTime.Time.Equal(start) {
+			t.Errorf("expected last rotation time %s but got %v", start, rotatedToken.Status.LastRotationTime)
+		}
+
+		for _, elapsed := range []time.Duration{5 * time.Second, 30 * time.Minute} {
+			mockSplunk.createCalled, mockSplunk.deleteCalled = false, false
+			fakeClock.SetTime(start.Add(elapsed))
+			result, err := reconciler.Reconcile(t.Context(), request)
+			if err != nil {
+				t.Fatalf("unexpected error after %s: %s", elapsed, err)
+			}
+			if mockSplunk.createCalled || mockSplunk.deleteCalled {
+				t.Errorf("expected rotation after %s to be refused", elapsed)
+			}
+			if want := time.Hour - elapsed; result.RequeueAfter != want {
+				t.Errorf("expected requeue after %s but got %s", want, result.RequeueAfter)
+			}
+			if event := <-recorder.Events; !strings.Contains(event, "RotationTooSoon") {
+				t.Errorf("expected RotationTooSoon event but got %s", event)
+			}
+		}
+
+		fakeClock.SetTime(start.Add(time.Hour + time.Second))
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error after minimum interval: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Error("expected token to be rotated once the minimum interval passed")
+		}
+	})
+
+	t.Run("postpones rotation of a recently created SplunkToken", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.CreationTimestamp = metav1.NewTime(start.Add(-time.Minute))
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{
+				TokenMaxAge:         time.Second,
+				MinRotationInterval: time.Hour,
+			},
+			Clock: clocktesting.NewFakePassiveClock(start),
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if !splunkToken.DeletionTimestamp.IsZero() {
+			t.Error("expected SplunkToken not to be deleted for rotation")
+		}
+	})
 }
 
-func deleteSuccess() error {
-	return nil
+func TestReconcileSecretRotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	staleData := map[string][]byte{
+		"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = 00000000-0000-0000-0000-000000000000"),
+	}
+
+	tests := map[string]struct {
+		secretExists bool
+	}{
+		"replaces immutable Secret with new token value": {secretExists: true},
+		"recreates Secret deleted during rotation":       {secretExists: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			issuedAt := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			splunkToken.Status.TokenIssuedAt = &issuedAt
+			staleSecret := testTokenSecret(staleData)
+			staleSecret.Immutable = ptr.To(true)
+
+			objects := []runtime.Object{&splunkToken}
+			if tt.secretExists {
+				objects = append(objects, &staleSecret)
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			mockSplunk := mockSplunkClient{
+				create: createSuccess,
+				delete: deleteSuccess,
+			}
+
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:      time.Hour,
+					RotationStrategy: config.RotationStrategySecret,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if !mockSplunk.deleteCalled {
+				t.Error("should have deleted the stale HEC token")
+			}
+			if !mockSplunk.createCalled {
+				t.Error("should have called CreateToken")
+			}
+
+			hecSecret := getTokenSecret(t, fakeClient)
+			if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+				t.Errorf("expected Secret to contain new token value %s but got %s", testTokenValue, value)
+			}
+
+			var updatedToken stv1alpha1.SplunkToken
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &updatedToken); err != nil {
+				t.Fatalf("expected SplunkToken to be kept but got %s", err)
+			}
+			if !updatedToken.DeletionTimestamp.IsZero() {
+				t.Error("SplunkToken should not be deleted")
+			}
+			if updatedToken.Status.TokenIssuedAt == nil || !updatedToken.Status.TokenIssuedAt.After(issuedAt.Time) {
+				t.Fatalf("expected token issue time to be updated but got %v", updatedToken.Status.TokenIssuedAt)
+			}
+			wantRotated := updatedToken.Status.TokenIssuedAt.UTC().Format(time.RFC3339)
+			if rotated := hecSecret.Annotations[config.LastRotatedAnnotation]; rotated != wantRotated {
+				t.Errorf("expected Secret to be annotated as rotated at %s but got %q", wantRotated, rotated)
+			}
+		})
+	}
 }
 
-func deleteErrorIfCalled() error {
-	return errors.New("should not call DeleteToken")
+func TestReconcilePaused(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	staleToken := testSplunkToken()
+	staleToken.CreationTimestamp = metav1.NewTime(time.Now().Add(-3 * time.Hour))
+	deletingToken := testSplunkToken()
+	deletingToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	tests := []struct {
+		name  string
+		token stv1alpha1.SplunkToken
+	}{
+		{name: "does not create token while paused", token: testSplunkToken()},
+		{name: "does not rotate token while paused", token: staleToken},
+		{name: "does not delete token while paused", token: deletingToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&tt.token).
+				Build()
+
+			mockSplunk := mockSplunkClient{
+				create: createErrorIfCalled,
+				delete: deleteErrorIfCalled,
+			}
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, Paused: true},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Errorf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled || mockSplunk.deleteCalled {
+				t.Error("should not call Splunk while paused")
+			}
+
+			var resultToken stv1alpha1.SplunkToken
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken); err != nil {
+				t.Fatalf("error checking token: %s", err)
+			}
+			if _, found := resultToken.Annotations[config.AllowDeleteAnnotation]; found {
+				t.Error("SplunkToken should not be rotated while paused")
+			}
+			if !controllerutil.ContainsFinalizer(&resultToken, config.TokenFinalizer) {
+				t.Error("finalizer should not be removed while paused")
+			}
+			var hecSecret corev1.Secret
+			err := fakeClient.Get(t.Context(), types.NamespacedName{Namespace: request.Namespace, Name: config.OwnedObjectName}, &hecSecret)
+			if !kerrors.IsNotFound(err) {
+				t.Errorf("expected no token Secret while paused but got %v", err)
+			}
+		})
+	}
 }
 
-func testSplunkToken() stv1alpha1.SplunkToken {
-	token := stv1alpha1.SplunkToken{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:         request.Namespace,
-			Name:              request.Name,
-			CreationTimestamp: metav1.Now(),
-		},
-		Spec: stv1alpha1.SplunkTokenSpec{
-			Name: "<internal-cluster-id>",
-		},
+func TestReconcileTokenMetadataAnnotations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkToken := testSplunkToken()
+	splunkToken.Annotations = map[string]string{
+		config.TokenMetadataAnnotationPrefix + "owner":       "team-a",
+		config.TokenMetadataAnnotationPrefix + "environment": "staging",
+		config.TokenMetadataAnnotationPrefix:                 "no field name",
+		"example.com/unrelated":                              "ignored",
 	}
-	controllerutil.AddFinalizer(&token, config.TokenFinalizer)
-	return token
+
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+		WithRuntimeObjects(&splunkToken).
+		Build()
+
+	mockSplunk := mockSplunkClient{
+		create: createSuccess,
+		delete: deleteErrorIfCalled,
+	}
+
+	reconciler := SplunkTokenReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		SplunkApi:    &mockSplunk,
+		SplunkConfig: config.General{TokenMaxAge: time.Hour},
+	}
+
+	if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+		t.Fatalf("unexpected error during reconcile: %s", err)
+	}
+
+	wantMetadata := map[string]string{"owner": "team-a", "environment": "staging"}
+	if !maps.Equal(mockSplunk.createdToken.Metadata, wantMetadata) {
+		t.Errorf("expected token metadata %v but got %v", wantMetadata, mockSplunk.createdToken.Metadata)
+	}
+}
+
+func TestReconcileReuseExistingToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	const existingValue = "11111111-2222-3333-4444-555555555555"
+	createdAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	existingToken := func(spec stv1alpha1.SplunkTokenSpec) func() (*splunkapi.HECToken, error) {
+		return func() (*splunkapi.HECToken, error) {
+			return &splunkapi.HECToken{
+				Spec:    spec,
+				Value:   existingValue,
+				Details: splunkapi.TokenDetails{CreatedAt: createdAt},
+			}, nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		get        func() (*splunkapi.HECToken, error)
+		wantCreate bool
+		wantUpdate bool
+		wantValue  string
+	}{
+		{
+			name:      "reuses existing token with matching indexes",
+			get:       existingToken(stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"main"}}),
+			wantValue: existingValue,
+		},
+		{
+			name:       "updates indexes of existing token",
+			get:        existingToken(stv1alpha1.SplunkTokenSpec{DefaultIndex: "development", AllowedIndexes: []string{"development"}}),
+			wantUpdate: true,
+			wantValue:  existingValue,
+		},
+		{
+			name:       "issues new token when none exists",
+			get:        func() (*splunkapi.HECToken, error) { return nil, splunkapi.ErrNotFound },
+			wantCreate: true,
+			wantValue:  testTokenValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.DefaultIndex = "main"
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled, get: tt.get}
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				Recorder:     record.NewFakeRecorder(10),
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, ReuseExistingTokens: true},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			if updated := mockSplunk.updatedToken != nil; updated != tt.wantUpdate {
+				t.Errorf("expected UpdateToken called %t but got %t", tt.wantUpdate, updated)
+			}
+			if tt.wantUpdate && mockSplunk.updatedToken.Spec.DefaultIndex != "main" {
+				t.Errorf("expected existing token updated to default index main but got %s", mockSplunk.updatedToken.Spec.DefaultIndex)
+			}
+			tokenSecret := getTokenSecret(t, fakeClient)
+			if value, _ := tokenValueFromSecret(&tokenSecret); value != tt.wantValue {
+				t.Errorf("expected Secret to contain token value %s but got %s", tt.wantValue, value)
+			}
+			if tt.wantCreate {
+				return
+			}
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+				t.Fatalf("error getting SplunkToken: %s", err)
+			}
+			if issuedAt := splunkToken.Status.TokenIssuedAt; issuedAt == nil || !issuedAt.Time.Equal(createdAt) {
+				t.Errorf("expected token issue time %s from Splunk but got %v", createdAt, issuedAt)
+			}
+		})
+	}
+}
+
+func TestReconcileTokenTags(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkConfig := config.General{
+		TokenMaxAge:   time.Hour,
+		UpdateIndexes: true,
+		TokenTags:     map[string]string{"businessUnit": "platform", "environment": "production"},
+		TokenTagLabels: map[string]string{
+			"businessUnit": "example.com/business-unit",
+			"costCenter":   "example.com/cost-center",
+			"team":         "example.com/team",
+		},
+	}
+	labels := map[string]string{
+		"example.com/business-unit": "security",
+		"example.com/cost-center":   "1234",
+		"example.com/unrelated":     "ignored",
+	}
+	wantTags := map[string]string{"businessUnit": "security", "costCenter": "1234", "environment": "production"}
+
+	t.Run("sends tags derived from labels with new token", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Labels = labels
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: splunkConfig,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !maps.Equal(mockSplunk.createdToken.Tags, wantTags) {
+			t.Errorf("expected token tags %v but got %v", wantTags, mockSplunk.createdToken.Tags)
+		}
+	})
+
+	t.Run("updates token whose tags differ", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Labels = labels
+		var tokenSecret corev1.Secret
+		(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(&splunkToken, testTokenValue, &tokenSecret)
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+			get: func() (*splunkapi.HECToken, error) {
+				return &splunkapi.HECToken{
+					Spec:  splunkToken.Spec,
+					Value: testTokenValue,
+					Tags:  map[string]string{"businessUnit": "platform", "environment": "production"},
+				}, nil
+			},
+		}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: splunkConfig,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.updatedToken == nil {
+			t.Fatal("expected HEC token to be updated")
+		}
+		if !maps.Equal(mockSplunk.updatedToken.Tags, wantTags) {
+			t.Errorf("expected updated token tags %v but got %v", wantTags, mockSplunk.updatedToken.Tags)
+		}
+	})
+}
+
+// blockingDeleteClient counts the DeleteToken calls in flight, which block until release is closed.
+type blockingDeleteClient struct {
+	splunkapi.TokenManager
+
+	inflight    atomic.Int32
+	maxInflight atomic.Int32
+	release     chan struct{}
+}
+
+func (b *blockingDeleteClient) DeleteToken(ctx context.Context, name string) error {
+	n := b.inflight.Add(1)
+	defer b.inflight.Add(-1)
+	for {
+		current := b.maxInflight.Load()
+		if n <= current || b.maxInflight.CompareAndSwap(current, n) {
+			break
+		}
+	}
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestReconcileConcurrentDeletions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name         string
+		concurrency  int
+		wantInflight int32
+	}{
+		{name: "finalizes one SplunkToken at a time by default", wantInflight: 1},
+		{name: "finalizes SplunkTokens concurrently up to the limit", concurrency: 3, wantInflight: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const tokenCount = 6
+			var objects []runtime.Object
+			var requests []reconcile.Request
+			for i := range tokenCount {
+				splunkToken := testSplunkToken()
+				splunkToken.Name = fmt.Sprintf("token-%d", i)
+				splunkToken.Spec.Name = fmt.Sprintf("cluster-%d", i)
+				splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				objects = append(objects, &splunkToken)
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&splunkToken)})
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			splunk := &blockingDeleteClient{release: make(chan struct{})}
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    splunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour, ConcurrentDeletions: tt.concurrency},
+			}
+
+			// reconcile the deleted SplunkTokens with as many workers as the finalizer controller runs
+			queue := make(chan reconcile.Request, len(requests))
+			for _, req := range requests {
+				queue <- req
+			}
+			close(queue)
+			var wg sync.WaitGroup
+			errs := make(chan error, len(requests))
+			for range max(1, tt.concurrency) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for req := range queue {
+						if _, err := reconciler.Reconcile(t.Context(), req); err != nil {
+							errs <- err
+						}
+					}
+				}()
+			}
+
+			deadline := time.After(5 * time.Second)
+			for splunk.inflight.Load() < tt.wantInflight {
+				select {
+				case <-deadline:
+					t.Fatalf("expected %d concurrent deletions but got %d", tt.wantInflight, splunk.inflight.Load())
+				case <-time.After(time.Millisecond):
+				}
+			}
+			close(splunk.release)
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Errorf("unexpected error during reconcile: %s", err)
+			}
+
+			if got := splunk.maxInflight.Load(); got != tt.wantInflight {
+				t.Errorf("expected at most %d concurrent deletions but got %d", tt.wantInflight, got)
+			}
+			for _, req := range requests {
+				var splunkToken stv1alpha1.SplunkToken
+				if err := fakeClient.Get(t.Context(), req.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+					t.Errorf("expected finalized SplunkToken %s to be removed but got %v", req.Name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsFinalizing(t *testing.T) {
+	deletingToken := testSplunkToken()
+	deletingToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	splunkToken := testSplunkToken()
+
+	finalizing := predicate.NewPredicateFuncs(isFinalizing)
+	if !finalizing.Update(event.UpdateEvent{ObjectOld: &splunkToken, ObjectNew: &deletingToken}) {
+		t.Error("expected SplunkToken being deleted to be handed to the finalizer controller")
+	}
+	if predicate.Not(finalizing).Update(event.UpdateEvent{ObjectOld: &splunkToken, ObjectNew: &deletingToken}) {
+		t.Error("expected SplunkToken being deleted to be left out of the token controller")
+	}
+	if finalizing.Create(event.CreateEvent{Object: &splunkToken}) {
+		t.Error("expected SplunkToken not being deleted to be left out of the finalizer controller")
+	}
+}
+
+func TestReconcileDeleteRetries(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	// failingDelete fails the first failures calls to DeleteToken, then succeeds.
+	failingDelete := func(failures int, calls *int) func() error {
+		return func() error {
+			*calls++
+			if *calls <= failures {
+				return errors.New("connection reset")
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name          string
+		failures      int
+		wantCalls     int
+		wantError     bool
+		wantFinalizer bool
+	}{
+		{name: "removes finalizer when delete succeeds within retry budget", failures: 2, wantCalls: 3},
+		{name: "requeues with error when retry budget is exhausted", failures: 5, wantCalls: 4, wantError: true, wantFinalizer: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			var calls int
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: failingDelete(tt.failures, &calls)},
+				SplunkConfig: config.General{
+					TokenMaxAge:        time.Hour,
+					DeleteRetries:      3,
+					DeleteRetryBackoff: time.Millisecond,
+				},
+			}
+
+			_, err := reconciler.Reconcile(t.Context(), request)
+			if gotError := err != nil; gotError != tt.wantError {
+				t.Errorf("expected error %t but got %v", tt.wantError, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d DeleteToken calls but got %d", tt.wantCalls, calls)
+			}
+
+			var resultToken stv1alpha1.SplunkToken
+			err = fakeClient.Get(t.Context(), request.NamespacedName, &resultToken)
+			if hasFinalizer := err == nil && controllerutil.ContainsFinalizer(&resultToken, config.TokenFinalizer); hasFinalizer != tt.wantFinalizer {
+				t.Errorf("expected finalizer present %t but got %t", tt.wantFinalizer, hasFinalizer)
+			}
+		})
+	}
+
+	t.Run("stops retrying once reconcile retry budget is spent", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		var calls int
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: failingDelete(5, &calls)},
+			SplunkConfig: config.General{
+				TokenMaxAge:        time.Hour,
+				DeleteRetries:      3,
+				DeleteRetryBackoff: time.Millisecond,
+				RetryBudget:        1,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error once retry budget was spent")
+		}
+		if calls != 2 {
+			t.Errorf("expected DeleteToken and a single retry but got %d calls", calls)
+		}
+	})
+
+	t.Run("stops waiting to retry once the reconcile times out", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		var calls int
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: failingDelete(5, &calls)},
+			SplunkConfig: config.General{
+				TokenMaxAge:        time.Hour,
+				DeleteRetries:      3,
+				DeleteRetryBackoff: time.Hour,
+				ReconcileTimeout:   10 * time.Millisecond,
+			},
+		}
+
+		start := time.Now()
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error once the reconcile timed out")
+		}
+		if elapsed := time.Since(start); elapsed > time.Minute {
+			t.Errorf("expected reconcile to end at its timeout but it took %s", elapsed)
+		}
+		if calls != 1 {
+			t.Errorf("expected a single DeleteToken call before the timeout but got %d", calls)
+		}
+	})
+
+	t.Run("does not retry forbidden errors", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: func() error { return splunkapi.ErrForbidden },
+		}
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:        time.Hour,
+				DeleteRetries:      3,
+				DeleteRetryBackoff: time.Hour,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("expected forbidden error to be handled but got %s", err)
+		}
+	})
+}
+
+func TestReconcileFinalizerTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name          string
+		deletingFor   time.Duration
+		wantError     bool
+		wantFinalizer bool
+	}{
+		{name: "keeps finalizer within timeout", deletingFor: 10 * time.Minute, wantError: true, wantFinalizer: true},
+		{name: "removes finalizer after timeout", deletingFor: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-tt.deletingFor)}
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			recorder := record.NewFakeRecorder(1)
+			orphanedBefore := counterValue(t, metrics.OrphanedTokens)
+			reconciler := SplunkTokenReconciler{
+				Client:   fakeClient,
+				Scheme:   scheme,
+				Recorder: recorder,
+				SplunkApi: &mockSplunkClient{
+					create: createErrorIfCalled,
+					delete: func() error { return errors.New("connection reset") },
+				},
+				SplunkConfig: config.General{
+					TokenMaxAge:      time.Hour,
+					FinalizerTimeout: time.Hour,
+				},
+			}
+
+			_, err := reconciler.Reconcile(t.Context(), request)
+			if gotError := err != nil; gotError != tt.wantError {
+				t.Errorf("expected error %t but got %v", tt.wantError, err)
+			}
+			var resultToken stv1alpha1.SplunkToken
+			err = fakeClient.Get(t.Context(), request.NamespacedName, &resultToken)
+			if hasFinalizer := err == nil && controllerutil.ContainsFinalizer(&resultToken, config.TokenFinalizer); hasFinalizer != tt.wantFinalizer {
+				t.Errorf("expected finalizer present %t but got %t", tt.wantFinalizer, hasFinalizer)
+			}
+
+			wantOrphaned := 0.0
+			if !tt.wantFinalizer {
+				wantOrphaned = 1
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "TokenOrphaned") {
+						t.Errorf("expected TokenOrphaned event but got %s", event)
+					}
+				default:
+					t.Error("expected a TokenOrphaned event")
+				}
+			}
+			if got := counterValue(t, metrics.OrphanedTokens) - orphanedBefore; got != wantOrphaned {
+				t.Errorf("expected orphaned token count to increase by %v but got %v", wantOrphaned, got)
+			}
+		})
+	}
+}
+
+func TestReconcileDuplicateToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name        string
+		policy      string
+		wantCreate  bool
+		wantEvent   string
+		wantWarning bool
+	}{
+		{name: "manages extra SplunkToken without policy", policy: "", wantCreate: true},
+		{name: "adopts extra SplunkToken", policy: config.DuplicateTokenPolicyAdopt, wantCreate: true, wantEvent: "DuplicateSplunkToken"},
+		{name: "ignores extra SplunkToken", policy: config.DuplicateTokenPolicyIgnore, wantEvent: "DuplicateSplunkToken"},
+		{name: "reports extra SplunkToken as error", policy: config.DuplicateTokenPolicyError, wantEvent: "DuplicateSplunkToken", wantWarning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonicalToken := testSplunkToken()
+			extraToken := testSplunkToken()
+			extraToken.Name = "extra"
+			extraToken.Spec.Name = "extra-token"
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&canonicalToken, &extraToken).
+				Build()
+
+			recorder := record.NewFakeRecorder(1)
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				Recorder:  recorder,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:          time.Hour,
+					DuplicateTokenPolicy: tt.policy,
+				},
+			}
+
+			extraRequest := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&extraToken)}
+			if _, err := reconciler.Reconcile(t.Context(), extraRequest); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if tt.wantEvent == "" {
+					t.Errorf("expected no event but got %s", event)
+				} else if !strings.Contains(event, tt.wantEvent) {
+					t.Errorf("expected %s event but got %s", tt.wantEvent, event)
+				} else if isWarning := strings.HasPrefix(event, corev1.EventTypeWarning); isWarning != tt.wantWarning {
+					t.Errorf("expected warning event %t but got %s", tt.wantWarning, event)
+				}
+			default:
+				if tt.wantEvent != "" {
+					t.Errorf("expected %s event", tt.wantEvent)
+				}
+			}
+
+			if tt.wantCreate {
+				return
+			}
+			// a skipped extra SplunkToken leaves the canonical SplunkToken to be managed
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if !mockSplunk.createCalled {
+				t.Error("expected CreateToken to be called for the canonical SplunkToken")
+			}
+		})
+	}
+
+	t.Run("keeps separate Secrets for adopted SplunkTokens", func(t *testing.T) {
+		canonicalToken := testSplunkToken()
+		canonicalToken.UID = "canonical-uid"
+		extraToken := testSplunkToken()
+		extraToken.Name = "extra"
+		extraToken.UID = "extra-uid"
+		extraToken.Spec.Name = "extra-token"
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&canonicalToken, &extraToken).
+			Build()
+
+		tokenValues := map[string]string{
+			canonicalToken.Spec.Name: testTokenValue,
+			extraToken.Spec.Name:     "9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e6f",
+		}
+		mockSplunk := mockSplunkClient{delete: deleteErrorIfCalled}
+		mockSplunk.create = func() (*splunkapi.HECToken, error) {
+			token, _ := createSuccess()
+			token.Value = tokenValues[mockSplunk.createdToken.Spec.Name]
+			return token, nil
+		}
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(10),
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:          time.Hour,
+				DuplicateTokenPolicy: config.DuplicateTokenPolicyAdopt,
+			},
+		}
+
+		extraRequest := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&extraToken)}
+		for _, req := range []reconcile.Request{extraRequest, request, extraRequest, request} {
+			if _, err := reconciler.Reconcile(t.Context(), req); err != nil {
+				t.Fatalf("unexpected error during reconcile of %s: %s", req, err)
+			}
+		}
+
+		for _, tokenObject := range []stv1alpha1.SplunkToken{canonicalToken, extraToken} {
+			var tokenSecret corev1.Secret
+			secretKey := types.NamespacedName{Namespace: tokenObject.Namespace, Name: tokenSecretName(reconciler.SplunkConfig, &tokenObject)}
+			if err := fakeClient.Get(t.Context(), secretKey, &tokenSecret); err != nil {
+				t.Fatalf("error getting Secret of SplunkToken %s: %s", tokenObject.Name, err)
+			}
+			if value, _ := tokenValueFromSecret(&tokenSecret); value != tokenValues[tokenObject.Spec.Name] {
+				t.Errorf("expected Secret %s to contain token value %s but got %s", tokenSecret.Name, tokenValues[tokenObject.Spec.Name], value)
+			}
+			if !metav1.IsControlledBy(&tokenSecret, &tokenObject) {
+				t.Errorf("expected Secret %s to be owned by SplunkToken %s but got %v", tokenSecret.Name, tokenObject.Name, tokenSecret.OwnerReferences)
+			}
+		}
+	})
+}
+
+func TestReconcileEmptyTokenName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	for _, name := range []string{"", "  "} {
+		t.Run(fmt.Sprintf("does not create token named %q", name), func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.Name = name
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled}
+			recorder := record.NewFakeRecorder(1)
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				Recorder:     recorder,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled {
+				t.Error("should not have created a token without a name")
+			}
+			if event := <-recorder.Events; !strings.HasPrefix(event, corev1.EventTypeWarning) || !strings.Contains(event, "MissingTokenName") {
+				t.Errorf("expected MissingTokenName warning but got %s", event)
+			}
+			var tokenSecret corev1.Secret
+			err := fakeClient.Get(t.Context(), types.NamespacedName{Namespace: request.Namespace, Name: config.OwnedObjectName}, &tokenSecret)
+			if !kerrors.IsNotFound(err) {
+				t.Errorf("expected no token Secret but got %v", err)
+			}
+		})
+	}
+
+	t.Run("finalizes SplunkToken without deleting a token", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.Name = ""
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.deleteCalled {
+			t.Error("should not have called DeleteToken with an empty name")
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+			t.Errorf("expected finalized SplunkToken to be removed but got %v", err)
+		}
+	})
+}
+
+func TestReconcileNamespaceFilter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		allowed    []string
+		denied     []string
+		deleting   bool
+		wantCreate bool
+		wantDelete bool
+	}{
+		{name: "manages every namespace without lists", wantCreate: true},
+		{name: "manages allowed namespace", allowed: []string{"other", request.Namespace}, wantCreate: true},
+		{name: "skips namespace not allowed", allowed: []string{"other"}},
+		{name: "skips denied namespace", denied: []string{request.Namespace}},
+		{name: "skips allowed namespace that is also denied", allowed: []string{request.Namespace}, denied: []string{request.Namespace}},
+		{name: "finalizes SplunkToken in denied namespace", denied: []string{request.Namespace}, deleting: true, wantDelete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			if tt.deleting {
+				splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteSuccess}
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:       time.Hour,
+					AllowedNamespaces: tt.allowed,
+					DeniedNamespaces:  tt.denied,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			if mockSplunk.deleteCalled != tt.wantDelete {
+				t.Errorf("expected DeleteToken called %t but got %t", tt.wantDelete, mockSplunk.deleteCalled)
+			}
+		})
+	}
+}
+
+func TestReconcileUpdateConflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name          string
+		retries       int
+		wantError     bool
+		wantFinalizer bool
+	}{
+		{name: "retries conflicting update with latest version", retries: 2, wantFinalizer: true},
+		{name: "fails on conflict when retries are disabled", retries: 0, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Finalizers = nil
+
+			var concurrentWrite bool
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						if _, isToken := obj.(*stv1alpha1.SplunkToken); isToken && !concurrentWrite {
+							// another writer changes the SplunkToken first, so this update is stale
+							concurrentWrite = true
+							var latest stv1alpha1.SplunkToken
+							if err := cl.Get(ctx, client.ObjectKeyFromObject(obj), &latest); err != nil {
+								return err
+							}
+							metav1.SetMetaDataLabel(&latest.ObjectMeta, "other-writer", "true")
+							if err := cl.Update(ctx, &latest); err != nil {
+								return err
+							}
+						}
+						return cl.Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+				SplunkConfig: config.General{
+					TokenMaxAge:           time.Hour,
+					UpdateConflictRetries: tt.retries,
+				},
+			}
+
+			_, err := reconciler.Reconcile(t.Context(), request)
+			if gotError := err != nil; gotError != tt.wantError {
+				t.Fatalf("expected error %t but got %v", tt.wantError, err)
+			}
+			if tt.wantError && !kerrors.IsConflict(err) {
+				t.Errorf("expected conflict error but got %v", err)
+			}
+
+			var resultToken stv1alpha1.SplunkToken
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken); err != nil {
+				t.Fatalf("error getting SplunkToken: %s", err)
+			}
+			if hasFinalizer := controllerutil.ContainsFinalizer(&resultToken, config.TokenFinalizer); hasFinalizer != tt.wantFinalizer {
+				t.Errorf("expected finalizer present %t but got %t", tt.wantFinalizer, hasFinalizer)
+			}
+			if resultToken.Labels["other-writer"] != "true" {
+				t.Error("expected the concurrent change to be kept")
+			}
+		})
+	}
+}
+
+func TestReconcileTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("stops retrying slow requests when the reconcile times out", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		var calls int
+		// each request fails after its own per-request timeout
+		slowDelete := func() error {
+			calls++
+			time.Sleep(20 * time.Millisecond)
+			return context.DeadlineExceeded
+		}
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: slowDelete},
+			SplunkConfig: config.General{
+				TokenMaxAge:        time.Hour,
+				DeleteRetries:      10,
+				DeleteRetryBackoff: 10 * time.Millisecond,
+				ReconcileTimeout:   100 * time.Millisecond,
+			},
+		}
+
+		start := time.Now()
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error when the reconcile times out")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected reconcile to stop near its 100ms budget but it took %s", elapsed)
+		}
+		if calls < 2 || calls > 10 {
+			t.Errorf("expected a few attempts within the reconcile budget but got %d", calls)
+		}
+	})
+}
+
+func TestReconcileDeleteSecretOnFinalize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		unmanaged  bool
+		wantSecret bool
+	}{
+		{name: "deletes managed Secret when enabled", enabled: true, wantSecret: false},
+		{name: "leaves Secret to garbage collection when disabled", enabled: false, wantSecret: true},
+		{name: "leaves unmanaged Secret in place", enabled: true, unmanaged: true, wantSecret: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+			})
+			if tt.unmanaged {
+				tokenSecret.Labels = nil
+			}
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess},
+				SplunkConfig: config.General{
+					TokenMaxAge:            time.Hour,
+					DeleteSecretOnFinalize: tt.enabled,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			err := fakeClient.Get(t.Context(), client.ObjectKeyFromObject(&tokenSecret), &corev1.Secret{})
+			if gotSecret := err == nil; gotSecret != tt.wantSecret {
+				t.Errorf("expected Secret to exist %t but got error %v", tt.wantSecret, err)
+			}
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+				t.Errorf("expected finalized SplunkToken to be removed but got %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcileDeleteTokenOnStoreFailure(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantDelete bool
+	}{
+		{name: "deletes HEC token when Secret creation fails", enabled: true, wantDelete: true},
+		{name: "keeps HEC token when disabled", enabled: false, wantDelete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if _, isSecret := obj.(*corev1.Secret); isSecret {
+							return errors.New("secret create failed")
+						}
+						return cl.Create(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteSuccess}
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:               time.Hour,
+					DeleteTokenOnStoreFailure: tt.enabled,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+				t.Fatal("expected reconcile to fail when the Secret cannot be created")
+			}
+			if !mockSplunk.createCalled {
+				t.Fatal("should have called CreateToken")
+			}
+			if mockSplunk.deleteCalled != tt.wantDelete {
+				t.Errorf("expected DeleteToken called %t but got %t", tt.wantDelete, mockSplunk.deleteCalled)
+			}
+			if tt.wantDelete && !slices.Equal(mockSplunk.deletedNames, []string{splunkToken.Spec.Name}) {
+				t.Errorf("expected HEC token %s to be deleted but deleted %v", splunkToken.Spec.Name, mockSplunk.deletedNames)
+			}
+		})
+	}
+}
+
+func TestReconcileAuditLog(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		deleting   bool
+		wantAction audit.Action
+	}{
+		{name: "records token creation", wantAction: audit.ActionCreate},
+		{name: "records token deletion", deleting: true, wantAction: audit.ActionDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			if tt.deleting {
+				splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			var out bytes.Buffer
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				Recorder:     record.NewFakeRecorder(1),
+				SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteSuccess},
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+				Audit:        audit.NewLogger(&out),
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if strings.Contains(out.String(), testTokenValue) {
+				t.Error("audit log contains the HEC token value")
+			}
+			var record audit.Record
+			if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+				t.Fatalf("error decoding audit record %q: %s", out.String(), err)
+			}
+			if record.Action != tt.wantAction {
+				t.Errorf("expected action %s but got %s", tt.wantAction, record.Action)
+			}
+			if record.Namespace != request.Namespace || record.Name != request.Name {
+				t.Errorf("expected SplunkToken %s but got %s/%s", request.NamespacedName, record.Namespace, record.Name)
+			}
+			if record.ClusterID != splunkToken.Spec.Name {
+				t.Errorf("expected cluster ID %s but got %s", splunkToken.Spec.Name, record.ClusterID)
+			}
+		})
+	}
+}
+
+func TestReconcileSecretOperationMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	creates := metrics.SecretOperations.WithLabelValues("create", "success")
+	createErrors := metrics.SecretOperations.WithLabelValues("create", "error")
+	createsBefore := counterValue(t, creates)
+	createErrorsBefore := counterValue(t, createErrors)
+
+	splunkToken := testSplunkToken()
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+		WithRuntimeObjects(&splunkToken).
+		Build()
+
+	reconciler := SplunkTokenReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+		SplunkConfig: config.General{TokenMaxAge: time.Hour},
+	}
+
+	if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+		t.Fatalf("unexpected error during reconcile: %s", err)
+	}
+	if got := counterValue(t, creates) - createsBefore; got != 1 {
+		t.Errorf("expected 1 successful Secret creation to be counted but got %v", got)
+	}
+	if got := counterValue(t, createErrors) - createErrorsBefore; got != 0 {
+		t.Errorf("expected no failed Secret creations to be counted but got %v", got)
+	}
+}
+
+func TestReconcileVerifyToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	oldValue := "00000000-0000-0000-0000-000000000000"
+	splunkConfig := config.General{
+		TokenMaxAge:    time.Hour,
+		SplunkInstance: "<splunk-collector-uri>",
+		VerifyInterval: 10 * time.Minute,
+	}
+
+	t.Run("recreates token deleted from Splunk", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		issuedAt := metav1.Now()
+		splunkToken.Status.TokenIssuedAt = &issuedAt
+		var currentSecret corev1.Secret
+		(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(&splunkToken, oldValue, &currentSecret)
+		currentSecret.ResourceVersion = "1"
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &currentSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+			get:    func() (*splunkapi.HECToken, error) { return nil, splunkapi.ErrNotFound },
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: splunkConfig,
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Error("should have called CreateToken")
+		}
+		if result.RequeueAfter != splunkConfig.VerifyInterval {
+			t.Errorf("expected requeue after %s but got %s", splunkConfig.VerifyInterval, result.RequeueAfter)
+		}
+		hecSecret := getTokenSecret(t, fakeClient)
+		if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+			t.Errorf("expected Secret to have new token value %s but got %s", testTokenValue, value)
+		}
+	})
+
+	t.Run("leaves existing token unchanged", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		var currentSecret corev1.Secret
+		(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(&splunkToken, oldValue, &currentSecret)
+		currentSecret.ResourceVersion = "1"
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &currentSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+			get:    createSuccess,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: splunkConfig,
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.getCalled {
+			t.Error("should have called GetToken")
+		}
+		if result.RequeueAfter != splunkConfig.VerifyInterval {
+			t.Errorf("expected requeue after %s but got %s", splunkConfig.VerifyInterval, result.RequeueAfter)
+		}
+		hecSecret := getTokenSecret(t, fakeClient)
+		if value, _ := tokenValueFromSecret(&hecSecret); value != oldValue {
+			t.Errorf("expected Secret to keep token value %s but got %s", oldValue, value)
+		}
+	})
+}
+
+func TestReconcileUpdateIndexes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		spec       stv1alpha1.SplunkTokenSpec
+		live       stv1alpha1.SplunkTokenSpec
+		fallback   string
+		wantUpdate bool
+	}{
+		{
+			name:       "updates token when allowed indexes differ",
+			spec:       stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit"}},
+			live:       stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"main"}},
+			wantUpdate: true,
+		},
+		{
+			name:       "updates token when default index differs",
+			spec:       stv1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			live:       stv1alpha1.SplunkTokenSpec{DefaultIndex: "development", AllowedIndexes: []string{"development"}},
+			wantUpdate: true,
+		},
+		{
+			name: "leaves token with matching indexes unchanged",
+			spec: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit"}},
+			live: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}},
+		},
+		{
+			name: "leaves token with whitespace around indexes unchanged",
+			spec: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main ", AllowedIndexes: []string{" audit"}},
+			live: stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}},
+		},
+		{
+			name:     "leaves token with fallback index unchanged",
+			live:     stv1alpha1.SplunkTokenSpec{DefaultIndex: "development", AllowedIndexes: []string{"development"}},
+			fallback: "development",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.DefaultIndex = tt.spec.DefaultIndex
+			splunkToken.Spec.AllowedIndexes = tt.spec.AllowedIndexes
+			splunkConfig := config.General{
+				TokenMaxAge:   time.Hour,
+				UpdateIndexes: true,
+				FallbackIndex: tt.fallback,
+			}
+			var tokenSecret corev1.Secret
+			(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(&splunkToken, testTokenValue, &tokenSecret)
+			tokenSecret.ResourceVersion = "42"
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			live := tt.live
+			live.Name = splunkToken.Spec.Name
+			mockSplunk := mockSplunkClient{
+				create: createErrorIfCalled,
+				delete: deleteErrorIfCalled,
+				get: func() (*splunkapi.HECToken, error) {
+					return &splunkapi.HECToken{Spec: live, Value: testTokenValue}, nil
+				},
+			}
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: splunkConfig,
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if updated := mockSplunk.updatedToken != nil; updated != tt.wantUpdate {
+				t.Fatalf("expected update %t but got %t", tt.wantUpdate, updated)
+			}
+			if tt.wantUpdate && !reflect.DeepEqual(mockSplunk.updatedToken.Spec, splunkToken.Spec) {
+				t.Errorf("expected token to be updated to %+v but got %+v", splunkToken.Spec, mockSplunk.updatedToken.Spec)
+			}
+			hecSecret := getTokenSecret(t, fakeClient)
+			if hecSecret.ResourceVersion != tokenSecret.ResourceVersion {
+				t.Errorf("expected token Secret to be left untouched but it was replaced")
+			}
+			if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+				t.Errorf("expected Secret to keep token value %s but got %s", testTokenValue, value)
+			}
+		})
+	}
+}
+
+func TestReconcileDisabledToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name          string
+		disabled      bool
+		wasDisabled   bool
+		wantUpdate    bool
+		updateIndexes bool
+	}{
+		{name: "disables HEC token", disabled: true, wantUpdate: true},
+		{name: "enables disabled HEC token", wasDisabled: true, wantUpdate: true},
+		{name: "leaves disabled HEC token unchanged", disabled: true, wasDisabled: true},
+		{name: "leaves enabled HEC token unchanged"},
+		{name: "disables HEC token with index update", disabled: true, wantUpdate: true, updateIndexes: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.DefaultIndex = "main"
+			splunkToken.Spec.Disabled = tt.disabled
+			splunkToken.Status.Disabled = tt.wasDisabled
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+			})
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			mockSplunk := mockSplunkClient{
+				create: createErrorIfCalled,
+				delete: deleteErrorIfCalled,
+				get: func() (*splunkapi.HECToken, error) {
+					live := splunkToken.Spec
+					live.Disabled = tt.wasDisabled
+					return &splunkapi.HECToken{Spec: live, Value: testTokenValue}, nil
+				},
+			}
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:   time.Hour,
+					UpdateIndexes: tt.updateIndexes,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			var wantUpdates int
+			if tt.wantUpdate {
+				wantUpdates = 1
+			}
+			if mockSplunk.updateCount != wantUpdates {
+				t.Fatalf("expected %d HEC token updates but got %d", wantUpdates, mockSplunk.updateCount)
+			}
+			if tt.wantUpdate && mockSplunk.updatedToken.Spec.Disabled != tt.disabled {
+				t.Errorf("expected HEC token to be updated with disabled %t", tt.disabled)
+			}
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+				t.Fatalf("error getting SplunkToken: %s", err)
+			}
+			if splunkToken.Status.Disabled != tt.disabled {
+				t.Errorf("expected status disabled %t but got %t", tt.disabled, splunkToken.Status.Disabled)
+			}
+		})
+	}
+
+	t.Run("records enablement of a newly issued HEC token", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.Disabled = true
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.createdToken.Spec.Disabled {
+			t.Error("expected HEC token to be created disabled")
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if !splunkToken.Status.Disabled {
+			t.Error("expected status to record the HEC token as disabled")
+		}
+	})
+}
+
+func TestReconcileSplunkInstance(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name         string
+		instance     string
+		wantDefault  bool
+		wantOther    bool
+		wantInstance string
+		wantURI      string
+	}{
+		{
+			name:         "creates token on configured instance by default",
+			wantDefault:  true,
+			wantInstance: "default-stack",
+			wantURI:      "https://http-inputs-default-stack.splunkcloud.com:443",
+		},
+		{
+			name:         "creates token on configured instance selected by name",
+			instance:     "default-stack",
+			wantDefault:  true,
+			wantInstance: "default-stack",
+			wantURI:      "https://http-inputs-default-stack.splunkcloud.com:443",
+		},
+		{
+			name:         "creates token on selected instance",
+			instance:     "other-stack",
+			wantOther:    true,
+			wantInstance: "other-stack",
+			wantURI:      "https://http-inputs-other-stack.splunkcloud.com:443",
+		},
+		{
+			name:     "skips SplunkToken selecting unknown instance",
+			instance: "unknown-stack",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.SplunkInstance = tt.instance
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			defaultSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			otherSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			recorder := record.NewFakeRecorder(1)
+			reconciler := SplunkTokenReconciler{
+				Client:          fakeClient,
+				Scheme:          scheme,
+				Recorder:        recorder,
+				SplunkApi:       &defaultSplunk,
+				SplunkInstances: map[string]splunkapi.TokenManager{"other-stack": &otherSplunk},
+				SplunkConfig:    config.General{TokenMaxAge: time.Hour, SplunkInstance: "default-stack"},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if defaultSplunk.createCalled != tt.wantDefault {
+				t.Errorf("expected token creation on configured instance %t but got %t", tt.wantDefault, defaultSplunk.createCalled)
+			}
+			if otherSplunk.createCalled != tt.wantOther {
+				t.Errorf("expected token creation on selected instance %t but got %t", tt.wantOther, otherSplunk.createCalled)
+			}
+			if tt.wantURI == "" {
+				if event := <-recorder.Events; !strings.Contains(event, "UnknownSplunkInstance") {
+					t.Errorf("expected UnknownSplunkInstance event but got %s", event)
+				}
+				return
+			}
+			for _, created := range []splunkapi.HECToken{defaultSplunk.createdToken, otherSplunk.createdToken} {
+				if created.Spec.SplunkInstance != "" {
+					t.Errorf("expected Splunk instance not to be sent to Splunk but got %s", created.Spec.SplunkInstance)
+				}
+			}
+			tokenSecret := getTokenSecret(t, fakeClient)
+			if outputs := string(tokenSecret.Data["outputs.conf"]); !strings.Contains(outputs, "uri = "+tt.wantURI) {
+				t.Errorf("expected Secret to send logs to %s but got %s", tt.wantURI, outputs)
+			}
+			if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+				t.Fatalf("error getting SplunkToken: %s", err)
+			}
+			if splunkToken.Status.SplunkInstance != tt.wantInstance {
+				t.Errorf("expected status Splunk instance %s but got %s", tt.wantInstance, splunkToken.Status.SplunkInstance)
+			}
+			if splunkToken.Status.CollectorURI != tt.wantURI {
+				t.Errorf("expected status collector URI %s but got %s", tt.wantURI, splunkToken.Status.CollectorURI)
+			}
+		})
+	}
+
+	t.Run("finalizes SplunkToken selecting removed instance", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.SplunkInstance = "removed-stack"
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		recorder := record.NewFakeRecorder(1)
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     recorder,
+			SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{TokenMaxAge: time.Hour, SplunkInstance: "default-stack"},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+			t.Errorf("expected finalized SplunkToken to be removed but got %v", err)
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "TokenOrphaned") {
+			t.Errorf("expected TokenOrphaned event but got %s", event)
+		}
+	})
+}
+
+func TestReconcileTargetStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkToken := testSplunkToken()
+	splunkToken.Spec.SplunkInstance = "other-stack"
+	splunkConfig := config.General{TokenMaxAge: time.Hour, SplunkInstance: "default-stack"}
+	var tokenSecret corev1.Secret
+	(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(&splunkToken, testTokenValue, &tokenSecret)
+
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+		WithRuntimeObjects(&splunkToken, &tokenSecret).
+		Build()
+
+	otherSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled}
+	reconciler := SplunkTokenReconciler{
+		Client:          fakeClient,
+		Scheme:          scheme,
+		SplunkApi:       &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+		SplunkInstances: map[string]splunkapi.TokenManager{"other-stack": &otherSplunk},
+		SplunkConfig:    splunkConfig,
+	}
+
+	if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+		t.Fatalf("unexpected error during reconcile: %s", err)
+	}
+	if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+		t.Fatalf("error getting SplunkToken: %s", err)
+	}
+	want := stv1alpha1.SplunkTokenStatus{
+		SplunkInstance: "other-stack",
+		CollectorURI:   "https://http-inputs-other-stack.splunkcloud.com:443",
+	}
+	if splunkToken.Status.SplunkInstance != want.SplunkInstance || splunkToken.Status.CollectorURI != want.CollectorURI {
+		t.Errorf("expected status of existing token to record %s at %s but got %s at %s", want.SplunkInstance, want.CollectorURI,
+			splunkToken.Status.SplunkInstance, splunkToken.Status.CollectorURI)
+	}
+}
+
+func TestReconcileEnterpriseTargetStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkToken := testSplunkToken()
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+		WithRuntimeObjects(&splunkToken).
+		Build()
+
+	enterpriseHECURL := "https://splunk.example.com:8088"
+	reconciler := SplunkTokenReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		Recorder:     record.NewFakeRecorder(10),
+		SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+		SplunkConfig: config.General{TokenMaxAge: time.Hour, SecretHECURL: true, EnterpriseHECURL: enterpriseHECURL},
+	}
+
+	if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+		t.Fatalf("unexpected error during reconcile: %s", err)
+	}
+	hecSecret := getTokenSecret(t, fakeClient)
+	if outputsConf := string(hecSecret.Data["outputs.conf"]); !strings.HasSuffix(outputsConf, "\nuri = "+enterpriseHECURL) {
+		t.Errorf("expected outputs.conf to point at %s but got\n%s", enterpriseHECURL, outputsConf)
+	}
+	if hecURL := string(hecSecret.Data[config.HECURLDataKey]); hecURL != enterpriseHECURL {
+		t.Errorf("expected hec_url %s but got %s", enterpriseHECURL, hecURL)
+	}
+	if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+		t.Fatalf("error getting SplunkToken: %s", err)
+	}
+	if splunkToken.Status.CollectorURI != enterpriseHECURL {
+		t.Errorf("expected status to record collector URI %s but got %s", enterpriseHECURL, splunkToken.Status.CollectorURI)
+	}
+}
+
+func TestReconcileUnmanagedSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	outdatedData := map[string][]byte{
+		"splunk.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+	}
+
+	t.Run("ignores same-named Secret without managed label", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		unmanagedSecret := testTokenSecret(outdatedData)
+		unmanagedSecret.Labels = nil
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &unmanagedSecret).
+			Build()
+
+		recorder := record.NewFakeRecorder(1)
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     recorder,
+			SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		hecSecret := getTokenSecret(t, fakeClient)
+		if hecSecret.ResourceVersion != unmanagedSecret.ResourceVersion || !maps.EqualFunc(hecSecret.Data, outdatedData, bytes.Equal) {
+			t.Error("expected unmanaged Secret to be left unchanged")
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "SecretConflict") {
+			t.Errorf("expected SecretConflict event but got %s", event)
+		}
+	})
+
+	t.Run("does not replace unmanaged Secret created after cached read", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		unmanagedSecret := testTokenSecret(outdatedData)
+		unmanagedSecret.Labels = nil
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &unmanagedSecret).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:       &staleSecretClient{Client: fakeClient},
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error for unmanaged Secret")
+		}
+		hecSecret := getTokenSecret(t, fakeClient)
+		if !maps.EqualFunc(hecSecret.Data, outdatedData, bytes.Equal) {
+			t.Error("expected unmanaged Secret to be left unchanged")
+		}
+	})
+
+	t.Run("manages unlabeled Secret controlled by the SplunkToken", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.UID = "splunk-token-uid"
+		legacySecret := testTokenSecret(outdatedData)
+		legacySecret.Labels = nil
+		if err := controllerutil.SetControllerReference(&splunkToken, &legacySecret, scheme); err != nil {
+			t.Fatalf("error setting controller reference: %s", err)
+		}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &legacySecret).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		hecSecret := getTokenSecret(t, fakeClient)
+		if _, found := hecSecret.Data[config.SecretDataKey]; !found {
+			t.Errorf("expected Secret to be regenerated under %s but got keys %v", config.SecretDataKey, slices.Sorted(maps.Keys(hecSecret.Data)))
+		}
+		if hecSecret.Labels[config.ManagedSecretLabel] != "true" {
+			t.Error("expected regenerated Secret to have managed label")
+		}
+	})
+}
+
+func TestTokenSpecIndexNormalization(t *testing.T) {
+	tests := []struct {
+		name        string
+		lowercase   bool
+		spec        stv1alpha1.SplunkTokenSpec
+		fallback    string
+		wantDefault string
+		wantAllowed []string
+	}{
+		{
+			name:        "trims whitespace",
+			spec:        stv1alpha1.SplunkTokenSpec{DefaultIndex: " main\t", AllowedIndexes: []string{"audit ", " infra"}},
+			wantDefault: "main",
+			wantAllowed: []string{"audit", "infra"},
+		},
+		{
+			name:        "keeps case by default",
+			spec:        stv1alpha1.SplunkTokenSpec{DefaultIndex: "Main", AllowedIndexes: []string{"Audit", "audit"}},
+			wantDefault: "Main",
+			wantAllowed: []string{"Audit", "audit"},
+		},
+		{
+			name:        "lowercases and drops duplicates when configured",
+			lowercase:   true,
+			spec:        stv1alpha1.SplunkTokenSpec{DefaultIndex: "Main", AllowedIndexes: []string{"Audit", "audit ", "AUDIT"}},
+			wantDefault: "main",
+			wantAllowed: []string{"audit"},
+		},
+		{
+			name:        "drops blank allowed indexes",
+			spec:        stv1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"  ", "audit"}},
+			wantDefault: "main",
+			wantAllowed: []string{"audit"},
+		},
+		{
+			name:        "normalizes fallback index",
+			lowercase:   true,
+			fallback:    "Development ",
+			wantDefault: "development",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Spec.DefaultIndex = tt.spec.DefaultIndex
+			splunkToken.Spec.AllowedIndexes = tt.spec.AllowedIndexes
+			reconciler := SplunkTokenReconciler{
+				SplunkConfig: config.General{LowercaseIndexes: tt.lowercase, FallbackIndex: tt.fallback},
+			}
+
+			spec := reconciler.tokenSpec(&splunkToken)
+			if spec.DefaultIndex != tt.wantDefault {
+				t.Errorf("expected default index %q but got %q", tt.wantDefault, spec.DefaultIndex)
+			}
+			if !slices.Equal(spec.AllowedIndexes, tt.wantAllowed) {
+				t.Errorf("expected allowed indexes %q but got %q", tt.wantAllowed, spec.AllowedIndexes)
+			}
+		})
+	}
+}
+
+func TestReconcileEmptyIndexes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("refuses to create token without indexes when required", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+		}
+		recorder := record.NewFakeRecorder(1)
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     recorder,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour, RequireIndex: true},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.createCalled {
+			t.Error("should not have called CreateToken")
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "NoIndexConfigured") {
+			t.Errorf("expected NoIndexConfigured event but got %s", event)
+		}
+	})
+
+	t.Run("uses fallback index for token without indexes", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:   time.Hour,
+				RequireIndex:  true,
+				FallbackIndex: "quarantine",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if got := mockSplunk.createdToken.Spec.DefaultIndex; got != "quarantine" {
+			t.Errorf("expected default index quarantine but got %s", got)
+		}
+	})
+
+	for name, rotate := range map[string]bool{
+		"does not delete token without indexes when rotating":              true,
+		"does not delete token without indexes when its Secret is missing": false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Finalizers = []string{config.TokenFinalizer}
+			issuedAt := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			splunkToken.Status.TokenIssuedAt = &issuedAt
+			objects := []runtime.Object{&splunkToken}
+			if rotate {
+				tokenSecret := testTokenSecret(map[string][]byte{
+					"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+				})
+				objects = append(objects, &tokenSecret)
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			mockSplunk := mockSplunkClient{
+				create: createErrorIfCalled,
+				delete: deleteErrorIfCalled,
+			}
+			recorder := record.NewFakeRecorder(1)
+
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				Recorder:  recorder,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:      time.Hour,
+					RotationStrategy: config.RotationStrategySecret,
+					RequireIndex:     true,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Errorf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.deleteCalled || mockSplunk.createCalled {
+				t.Error("should not have deleted or created the HEC token")
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, "NoIndexConfigured") {
+					t.Errorf("expected NoIndexConfigured event but got %s", event)
+				}
+			default:
+				t.Error("expected NoIndexConfigured event")
+			}
+		})
+	}
+
+	t.Run("keeps configured indexes", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.AllowedIndexes = []string{"audit"}
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:   time.Hour,
+				RequireIndex:  true,
+				FallbackIndex: "quarantine",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if got := mockSplunk.createdToken.Spec.DefaultIndex; got != "" {
+			t.Errorf("expected no default index but got %s", got)
+		}
+	})
+}
+
+func TestReconcileInvalidTokenValue(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	for name, value := range map[string]string{
+		"empty":     "",
+		"malformed": "not-a-guid",
+	} {
+		t.Run(fmt.Sprintf("fails reconcile without creating Secret for %s token value", name), func(t *testing.T) {
+			splunkToken := testSplunkToken()
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			mockSplunk := mockSplunkClient{
+				create: func() (*splunkapi.HECToken, error) {
+					token, _ := createSuccess()
+					token.Value = value
+					return token, nil
+				},
+				delete: deleteErrorIfCalled,
+			}
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+			}
+
+			before := counterValue(t, metrics.InvalidTokenValues)
+			if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+				t.Error("expected error during reconcile but did not get one")
+			}
+			if after := counterValue(t, metrics.InvalidTokenValues); after != before+1 {
+				t.Errorf("expected invalid token metric to increment from %v but got %v", before, after)
+			}
+
+			var hecSecret corev1.Secret
+			err := fakeClient.Get(t.Context(),
+				types.NamespacedName{
+					Namespace: request.Namespace,
+					Name:      config.OwnedObjectName,
+				},
+				&hecSecret)
+			if !kerrors.IsNotFound(err) {
+				t.Errorf("expected no Secret to be created, got Secret: %v, err: %s", hecSecret, err)
+			}
+		})
+	}
+}
+
+func TestReconcileEmptySecretToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name       string
+		value      string
+		enabled    bool
+		wantCreate bool
+	}{
+		{name: "reissues empty token value", value: "", enabled: true, wantCreate: true},
+		{name: "reissues whitespace token value", value: "  ", enabled: true, wantCreate: true},
+		{name: "keeps empty token value when disabled", value: "", enabled: false},
+		{name: "keeps valid token value", value: testTokenValue, enabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			tokenSecret := testTokenSecret(map[string][]byte{
+				"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + tt.value +
+					"\nuri = https://http-inputs-.splunkcloud.com:443\n"),
+			})
+			tokenSecret.Immutable = ptr.To(true)
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:        time.Hour,
+					ReissueEmptyTokens: tt.enabled,
+				},
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			if !tt.wantCreate {
+				return
+			}
+			hecSecret := getTokenSecret(t, fakeClient)
+			if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+				t.Errorf("expected Secret to contain reissued token value %s but got %q", testTokenValue, value)
+			}
+		})
+	}
+}
+
+func TestReconcileSecretMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("applies configured labels, annotations, and type to Secret", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			SplunkApi: &mockSplunkClient{
+				create: createSuccess,
+				delete: deleteErrorIfCalled,
+			},
+			SplunkConfig: config.General{
+				TokenMaxAge:          time.Hour,
+				SecretType:           "managed.openshift.io/splunk-hec",
+				SecretLabels:         map[string]string{"app.kubernetes.io/managed-by": config.OperatorName},
+				SecretAnnotations:    map[string]string{"example.com/owner": "sre"},
+				SecretClusterIDLabel: "api.openshift.com/id",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		wantLabels := map[string]string{
+			"app.kubernetes.io/managed-by": config.OperatorName,
+			"api.openshift.com/id":         splunkToken.Spec.Name,
+			config.ManagedSecretLabel:      "true",
+		}
+		if !maps.Equal(hecSecret.Labels, wantLabels) {
+			t.Errorf("expected labels %v but got %v", wantLabels, hecSecret.Labels)
+		}
+		if hecSecret.Annotations["example.com/owner"] != "sre" {
+			t.Errorf("expected annotation example.com/owner=sre but got %v", hecSecret.Annotations)
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		wantRotated := splunkToken.Status.TokenIssuedAt.UTC().Format(time.RFC3339)
+		if rotated := hecSecret.Annotations[config.LastRotatedAnnotation]; rotated != wantRotated {
+			t.Errorf("expected annotation %s=%s but got %q", config.LastRotatedAnnotation, wantRotated, rotated)
+		}
+		if hecSecret.Type != "managed.openshift.io/splunk-hec" {
+			t.Errorf("expected Secret type managed.openshift.io/splunk-hec but got %s", hecSecret.Type)
+		}
+	})
+
+	t.Run("adds HEC URL key when configured", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			SplunkApi: &mockSplunkClient{
+				create: createSuccess,
+				delete: deleteErrorIfCalled,
+			},
+			SplunkConfig: config.General{
+				TokenMaxAge:    time.Hour,
+				SplunkInstance: "<splunk-collector-uri>",
+				SecretHECURL:   true,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		if keys := slices.Sorted(maps.Keys(hecSecret.Data)); !slices.Equal(keys, []string{"hec_url", "outputs.conf"}) {
+			t.Errorf("expected Secret keys hec_url and outputs.conf but got %v", keys)
+		}
+		wantURL := "https://http-inputs-<splunk-collector-uri>.splunkcloud.com:443"
+		if string(hecSecret.Data["hec_url"]) != wantURL {
+			t.Errorf("expected hec_url %s but got %s", wantURL, hecSecret.Data["hec_url"])
+		}
+		if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+			t.Errorf("expected outputs.conf to contain token value %s but got %s", testTokenValue, value)
+		}
+	})
+
+	t.Run("adds index routing key when configured", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Spec.DefaultIndex = "main"
+		splunkToken.Spec.AllowedIndexes = []string{"audit", "main"}
+		splunkToken.Spec.Sourcetype = "osd:audit"
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			SplunkApi: &mockSplunkClient{
+				create: createSuccess,
+				delete: deleteErrorIfCalled,
+			},
+			SplunkConfig: config.General{
+				TokenMaxAge:        time.Hour,
+				SplunkInstance:     "<splunk-collector-uri>",
+				SecretIndexRouting: true,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		if keys := slices.Sorted(maps.Keys(hecSecret.Data)); !slices.Equal(keys, []string{"index_routing.conf", "outputs.conf"}) {
+			t.Errorf("expected Secret keys index_routing.conf and outputs.conf but got %v", keys)
+		}
+		wantRouting := "[index_routing]\ndefaultIndex = main\nallowedIndexes = audit,main\nsourcetype = osd:audit"
+		if got := string(hecSecret.Data["index_routing.conf"]); got != wantRouting {
+			t.Errorf("expected index routing\n%s\nbut got\n%s", wantRouting, got)
+		}
+		if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+			t.Errorf("expected outputs.conf to contain token value %s but got %s", testTokenValue, value)
+		}
+	})
+}
+
+func TestReconcileMutableSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	splunkConfig := config.General{TokenMaxAge: time.Hour, MutableSecrets: true}
+	issuedAt := metav1.NewTime(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	withoutIssueTime := func(splunkToken stv1alpha1.SplunkToken) *stv1alpha1.SplunkToken {
+		splunkToken.Status.TokenIssuedAt = nil
+		return &splunkToken
+	}
+
+	tests := []struct {
+		name          string
+		secret        func(splunkToken stv1alpha1.SplunkToken) corev1.Secret
+		wantRecreated bool
+	}{
+		{
+			name: "updates only an annotation in place",
+			secret: func(splunkToken stv1alpha1.SplunkToken) corev1.Secret {
+				var secret corev1.Secret
+				(&SplunkTokenReconciler{SplunkConfig: splunkConfig}).newSecretObject(withoutIssueTime(splunkToken), testTokenValue, &secret)
+				return secret
+			},
+		},
+		{
+			name: "updates out of date data in place",
+			secret: func(splunkToken stv1alpha1.SplunkToken) corev1.Secret {
+				secret := testTokenSecret(map[string][]byte{
+					"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+				})
+				secret.Immutable = ptr.To(false)
+				return secret
+			},
+		},
+		{
+			name: "replaces immutable Secret with out of date data",
+			secret: func(splunkToken stv1alpha1.SplunkToken) corev1.Secret {
+				secret := testTokenSecret(map[string][]byte{
+					"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+				})
+				secret.Immutable = ptr.To(true)
+				return secret
+			},
+			wantRecreated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			splunkToken.Status.TokenIssuedAt = &issuedAt
+			tokenSecret := tt.secret(splunkToken)
+			tokenSecret.UID = "existing-secret"
+			tokenSecret.ResourceVersion = "1"
+
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken, &tokenSecret).
+				Build()
+
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+				SplunkConfig: splunkConfig,
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			hecSecret := getTokenSecret(t, fakeClient)
+			if recreated := hecSecret.UID != tokenSecret.UID; recreated != tt.wantRecreated {
+				t.Errorf("expected Secret recreated %t but got %t", tt.wantRecreated, recreated)
+			}
+			var wantSecret corev1.Secret
+			reconciler.newSecretObject(&splunkToken, testTokenValue, &wantSecret)
+			if !maps.EqualFunc(hecSecret.Data, wantSecret.Data, bytes.Equal) {
+				t.Errorf("expected Secret data %q but got %q", wantSecret.Data, hecSecret.Data)
+			}
+			if rotated := hecSecret.Annotations[config.LastRotatedAnnotation]; rotated != issuedAt.UTC().Format(time.RFC3339) {
+				t.Errorf("expected annotation %s=%s but got %q", config.LastRotatedAnnotation, issuedAt.UTC().Format(time.RFC3339), rotated)
+			}
+		})
+	}
+
+	t.Run("creates mutable Secret", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled},
+			SplunkConfig: splunkConfig,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if hecSecret := getTokenSecret(t, fakeClient); ptr.Deref(hecSecret.Immutable, false) {
+			t.Error("expected Secret to be mutable")
+		}
+	})
+}
+
+func TestReconcileNamespaceTokenLimit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	olderToken := func(name string, age time.Duration) *stv1alpha1.SplunkToken {
+		token := testSplunkToken()
+		token.Name = name
+		token.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		return &token
+	}
+
+	t.Run("refuses to create token beyond namespace limit", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, olderToken("first", 2*time.Minute), olderToken("second", time.Minute)).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+		}
+		recorder := record.NewFakeRecorder(1)
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  recorder,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:           time.Hour,
+				MaxTokensPerNamespace: 2,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.createCalled {
+			t.Error("should not have called CreateToken")
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "TokenLimitExceeded") {
+			t.Errorf("expected TokenLimitExceeded event but got %s", event)
+		}
+	})
+
+	t.Run("creates token within namespace limit", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, olderToken("newer", time.Minute)).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:           2 * time.Hour,
+				MaxTokensPerNamespace: 1,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Error("should have called CreateToken")
+		}
+	})
+}
+
+func TestReconcileLegacyFinalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	const legacyFinalizer = "managed.openshift.io/splunk-token"
+	splunkConfig := config.General{
+		TokenMaxAge:      time.Hour,
+		LegacyFinalizers: []string{legacyFinalizer},
+	}
+
+	t.Run("replaces legacy finalizer", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Finalizers = []string{legacyFinalizer}
+		tokenSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled},
+			SplunkConfig: splunkConfig,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		var resultToken stv1alpha1.SplunkToken
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &resultToken); err != nil {
+			t.Fatalf("error getting SplunkToken: %s", err)
+		}
+		if !slices.Equal(resultToken.Finalizers, []string{config.TokenFinalizer}) {
+			t.Errorf("expected finalizers [%s] but got %v", config.TokenFinalizer, resultToken.Finalizers)
+		}
+	})
+
+	t.Run("finalizes SplunkToken with legacy finalizer", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.Finalizers = []string{legacyFinalizer}
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteSuccess}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: splunkConfig,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.deleteCalled {
+			t.Error("should have deleted the HEC token")
+		}
+		if err := fakeClient.Get(t.Context(), request.NamespacedName, &splunkToken); !kerrors.IsNotFound(err) {
+			t.Errorf("expected finalized SplunkToken to be removed but got %v", err)
+		}
+	})
+}
+
+func TestReconcileSecretMigration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	outputsConf := []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue +
+		"\nuri = https://http-inputs-<splunk-collector-uri>.splunkcloud.com:443")
+
+	t.Run("moves token value to newly configured data key", func(t *testing.T) {
+		updates := metrics.SecretOperations.WithLabelValues("update", "success")
+		updatesBefore := counterValue(t, updates)
+		splunkToken := testSplunkToken()
+		oldSecret := testTokenSecret(map[string][]byte{"outputs.conf": outputsConf})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &oldSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:    time.Hour,
+				SplunkInstance: "<splunk-collector-uri>",
+				SecretDataKey:  "splunk.conf",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.createCalled {
+			t.Error("should not have called CreateToken")
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		if keys := slices.Sorted(maps.Keys(hecSecret.Data)); !slices.Equal(keys, []string{"splunk.conf"}) {
+			t.Errorf("expected Secret to only have key splunk.conf but got %v", keys)
+		}
+		if !bytes.Equal(hecSecret.Data["splunk.conf"], outputsConf) {
+			t.Errorf("expected token value to be preserved\ngot: %s\nwant: %s", hecSecret.Data["splunk.conf"], outputsConf)
+		}
+		if !metav1.IsControlledBy(&hecSecret, &splunkToken) {
+			t.Error("expected regenerated Secret to be controlled by the SplunkToken")
+		}
+		if got := counterValue(t, updates) - updatesBefore; got != 1 {
+			t.Errorf("expected 1 successful Secret update to be counted but got %v", got)
+		}
+	})
+
+	t.Run("leaves Secret in current format unchanged", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		currentSecret := testTokenSecret(map[string][]byte{"outputs.conf": outputsConf})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &currentSecret).
+			Build()
+
+		reconciler := SplunkTokenReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			SplunkApi: &mockSplunkClient{
+				create: createErrorIfCalled,
+				delete: deleteErrorIfCalled,
+			},
+			SplunkConfig: config.General{
+				TokenMaxAge:    time.Hour,
+				SplunkInstance: "<splunk-collector-uri>",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		if hecSecret.ResourceVersion != currentSecret.ResourceVersion {
+			t.Errorf("expected Secret to be unchanged, resource version went from %s to %s",
+				currentSecret.ResourceVersion, hecSecret.ResourceVersion)
+		}
+	})
+}
+
+func TestReconcileSecretRename(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	const newSecretName = "splunk-hec-token-v2"
+
+	t.Run("moves token value to the renamed Secret", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		oldSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &oldSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge: time.Hour,
+				SecretName:  newSecretName,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.createCalled {
+			t.Error("should not have called CreateToken")
+		}
+
+		var newSecret corev1.Secret
+		if err := fakeClient.Get(t.Context(), types.NamespacedName{Namespace: request.Namespace, Name: newSecretName}, &newSecret); err != nil {
+			t.Fatalf("error getting renamed Secret: %s", err)
+		}
+		if value, _ := tokenValueFromSecret(&newSecret); value != testTokenValue {
+			t.Errorf("expected renamed Secret to contain token value %s but got %s", testTokenValue, value)
+		}
+		if !metav1.IsControlledBy(&newSecret, &splunkToken) {
+			t.Error("expected renamed Secret to be controlled by the SplunkToken")
+		}
+		err := fakeClient.Get(t.Context(), types.NamespacedName{Namespace: request.Namespace, Name: config.OwnedObjectName}, &oldSecret)
+		if !kerrors.IsNotFound(err) {
+			t.Errorf("expected old Secret to be deleted but got %v", err)
+		}
+	})
+
+	t.Run("ignores unmanaged Secrets", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		unmanagedSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = 00000000-0000-0000-0000-000000000000"),
+		})
+		unmanagedSecret.Labels = nil
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &unmanagedSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  record.NewFakeRecorder(1),
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge: time.Hour,
+				SecretName:  newSecretName,
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Error("should have called CreateToken")
+		}
+		if err := fakeClient.Get(t.Context(), types.NamespacedName{Namespace: request.Namespace, Name: config.OwnedObjectName}, &unmanagedSecret); err != nil {
+			t.Errorf("expected unmanaged Secret to be kept but got %v", err)
+		}
+	})
+}
+
+func TestReconcileSecretAlreadyExists(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("replaces Secret created by an earlier reconcile", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		staleSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = 00000000-0000-0000-0000-000000000000"),
+		})
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &staleSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createSuccess,
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:    &staleSecretClient{Client: fakeClient},
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:    time.Hour,
+				SplunkInstance: "<splunk-collector-uri>",
+			},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.createCalled {
+			t.Error("should have called CreateToken")
+		}
+
+		hecSecret := getTokenSecret(t, fakeClient)
+		if value, _ := tokenValueFromSecret(&hecSecret); value != testTokenValue {
+			t.Errorf("expected Secret to contain new token value %s but got %s", testTokenValue, value)
+		}
+	})
+}
+
+func TestReconcileSecretDeleted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("issues a new token when the Secret is deleted", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		tokenValues := []string{testTokenValue, "9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e6f"}
+		var issued int
+		mockSplunk := mockSplunkClient{
+			create: func() (*splunkapi.HECToken, error) {
+				token, _ := createSuccess()
+				token.Value = tokenValues[issued]
+				issued += 1
+				return token, nil
+			},
+			delete: deleteSuccess,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.deleteCalled {
+			t.Error("should not have called DeleteToken when issuing the first token")
+		}
+		firstSecret := getTokenSecret(t, fakeClient)
+		if err := fakeClient.Delete(t.Context(), &firstSecret); err != nil {
+			t.Fatalf("error deleting Secret: %s", err)
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if !mockSplunk.deleteCalled {
+			t.Error("should have called DeleteToken so a new value is issued")
+		}
+		secondSecret := getTokenSecret(t, fakeClient)
+		if value, _ := tokenValueFromSecret(&secondSecret); value != tokenValues[1] {
+			t.Errorf("expected recreated Secret to have new token value %s but got %s", tokenValues[1], value)
+		}
+	})
+
+	t.Run("waits for a Secret missing only from the cache", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		issuedAt := metav1.Now()
+		splunkToken.Status.TokenIssuedAt = &issuedAt
+		splunkToken.Finalizers = []string{config.TokenFinalizer}
+		tokenSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = " + testTokenValue),
+		})
+
+		// the cache has not yet seen the Secret created along with the token
+		cachedClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+		apiReader := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken, &tokenSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createErrorIfCalled, delete: deleteErrorIfCalled}
+		reconciler := SplunkTokenReconciler{
+			Client:    cachedClient,
+			APIReader: apiReader,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:                      time.Hour,
+				TerminatingSecretRequeueInterval: 10 * time.Second,
+			},
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if mockSplunk.deleteCalled || mockSplunk.createCalled {
+			t.Error("should not revoke or issue the HEC token while the Secret exists on the API server")
+		}
+		if result.RequeueAfter != 10*time.Second {
+			t.Errorf("expected requeue after 10s but got %v", result.RequeueAfter)
+		}
+	})
+
+	t.Run("requeues while the Secret is being deleted", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		issuedAt := metav1.Now()
+		splunkToken.Status.TokenIssuedAt = &issuedAt
+		terminatingSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("[httpout]\nhttpEventCollectorToken = 00000000-0000-0000-0000-000000000000"),
+		})
+		terminatingSecret.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		terminatingSecret.Finalizers = []string{"test.managed.openshift.io/finalizer"}
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken, &terminatingSecret).
+			Build()
+
+		mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteSuccess}
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:                      time.Hour,
+				TerminatingSecretRequeueInterval: 10 * time.Second,
+			},
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		if result.RequeueAfter != 10*time.Second {
+			t.Errorf("expected requeue after 10s but got %v", result.RequeueAfter)
+		}
+		if mockSplunk.createCalled || mockSplunk.deleteCalled {
+			t.Error("should not call Splunk while the Secret is being deleted")
+		}
+
+		// garbage collection finishes and the Secret is removed
+		terminatingSecret = getTokenSecret(t, fakeClient)
+		terminatingSecret.Finalizers = nil
+		if err := fakeClient.Update(t.Context(), &terminatingSecret); err != nil {
+			t.Fatalf("error removing Secret finalizer: %s", err)
+		}
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Fatalf("unexpected error during reconcile: %s", err)
+		}
+		recreatedSecret := getTokenSecret(t, fakeClient)
+		if value, _ := tokenValueFromSecret(&recreatedSecret); value != testTokenValue {
+			t.Errorf("expected recreated Secret to have token value %s but got %s", testTokenValue, value)
+		}
+	})
+}
+
+func TestReconcileMaintenance(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	maintenance := fmt.Errorf("received error response 503-maintenance: %w", splunkapi.ErrMaintenance)
+
+	tests := []struct {
+		name        string
+		windows     []config.MaintenanceWindow
+		create      func() (*splunkapi.HECToken, error)
+		deleting    bool
+		wantRequeue time.Duration
+		wantCreate  bool
+	}{
+		{
+			name:        "postpones reconcile during maintenance window",
+			windows:     []config.MaintenanceWindow{{Start: now.Add(-time.Hour), End: now.Add(time.Hour)}},
+			create:      createErrorIfCalled,
+			wantRequeue: time.Hour,
+		},
+		{
+			name:        "postpones finalizing during maintenance window",
+			windows:     []config.MaintenanceWindow{{Start: now.Add(-time.Hour), End: now.Add(30 * time.Minute)}},
+			create:      createErrorIfCalled,
+			deleting:    true,
+			wantRequeue: 30 * time.Minute,
+		},
+		{
+			name:        "requeues slowly after maintenance error",
+			windows:     []config.MaintenanceWindow{{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}},
+			create:      func() (*splunkapi.HECToken, error) { return nil, maintenance },
+			wantRequeue: defaultMaintenanceRequeueInterval,
+			wantCreate:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			if tt.deleting {
+				splunkToken.DeletionTimestamp = &metav1.Time{Time: now}
+			}
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			mockSplunk := mockSplunkClient{create: tt.create, delete: deleteErrorIfCalled}
+			recorder := record.NewFakeRecorder(1)
+			reconciler := SplunkTokenReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				Recorder:  recorder,
+				SplunkApi: &mockSplunk,
+				SplunkConfig: config.General{
+					TokenMaxAge:        time.Hour,
+					MaintenanceWindows: tt.windows,
+				},
+				Clock: clocktesting.NewFakePassiveClock(now),
+			}
+
+			result, err := reconciler.Reconcile(t.Context(), request)
+			if err != nil {
+				t.Errorf("expected maintenance to be handled but got %s", err)
+			}
+			if result.RequeueAfter != tt.wantRequeue {
+				t.Errorf("expected requeue after %s but got %s", tt.wantRequeue, result.RequeueAfter)
+			}
+			if mockSplunk.createCalled != tt.wantCreate {
+				t.Errorf("expected CreateToken called %t but got %t", tt.wantCreate, mockSplunk.createCalled)
+			}
+			if mockSplunk.deleteCalled {
+				t.Error("should not have called DeleteToken")
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, corev1.EventTypeNormal) || !strings.Contains(event, "SplunkMaintenance") {
+					t.Errorf("expected normal SplunkMaintenance event but got %s", event)
+				}
+			default:
+				t.Error("expected SplunkMaintenance event")
+			}
+		})
+	}
+}
+
+func TestReconcileForbidden(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	forbidden := fmt.Errorf("received error response 403: %w", splunkapi.ErrForbidden)
+
+	t.Run("requeues slowly and records event when creation is forbidden", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: func() (*splunkapi.HECToken, error) { return nil, forbidden },
+			delete: deleteErrorIfCalled,
+		}
+		recorder := record.NewFakeRecorder(1)
+
+		reconciler := SplunkTokenReconciler{
+			Client:    fakeClient,
+			Scheme:    scheme,
+			Recorder:  recorder,
+			SplunkApi: &mockSplunk,
+			SplunkConfig: config.General{
+				TokenMaxAge:              time.Hour,
+				ForbiddenRequeueInterval: 10 * time.Minute,
+			},
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Errorf("expected forbidden error to be handled but got %s", err)
+		}
+		if result.RequeueAfter != 10*time.Minute {
+			t.Errorf("expected requeue after 10m but got %s", result.RequeueAfter)
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "PermissionDenied") {
+			t.Errorf("expected PermissionDenied event but got %s", event)
+		}
+	})
+
+	t.Run("uses default requeue interval when deletion is forbidden", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		splunkToken.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: createErrorIfCalled,
+			delete: func() error { return forbidden },
+		}
+		recorder := record.NewFakeRecorder(1)
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     recorder,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		result, err := reconciler.Reconcile(t.Context(), request)
+		if err != nil {
+			t.Errorf("expected forbidden error to be handled but got %s", err)
+		}
+		if result.RequeueAfter != defaultForbiddenRequeueInterval {
+			t.Errorf("expected requeue after %s but got %s", defaultForbiddenRequeueInterval, result.RequeueAfter)
+		}
+		if event := <-recorder.Events; !strings.Contains(event, "PermissionDenied") {
+			t.Errorf("expected PermissionDenied event but got %s", event)
+		}
+	})
+
+	t.Run("returns other Splunk errors for retry", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		mockSplunk := mockSplunkClient{
+			create: func() (*splunkapi.HECToken, error) { return nil, errors.New("server unavailable") },
+			delete: deleteErrorIfCalled,
+		}
+
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			Recorder:     record.NewFakeRecorder(1),
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error from reconcile")
+		}
+	})
+}
+
+type errorClient struct {
+	client.Client
+	err func() *kerrors.StatusError
+}
+
+func (e errorClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return e.err()
+}
+
+// staleSecretClient reports the token Secret as missing on the first Get,
+// as though the cache had not yet seen a Secret created by an earlier reconcile.
+type staleSecretClient struct {
+	client.Client
+	served bool
+}
+
+func (s *staleSecretClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && !s.served {
+		s.served = true
+		return objectNotFound()
+	}
+	return s.Client.Get(ctx, key, obj, opts...)
+}
+
+func objectNotFound() *kerrors.StatusError {
+	return kerrors.NewNotFound(schema.GroupResource{}, config.OwnedObjectName)
+}
+
+type mockSplunkClient struct {
+	splunkapi.TokenManager
+
+	createCalled bool
+	deleteCalled bool
+	deletedNames []string
+	createdToken splunkapi.HECToken
+	getCalled    bool
+	updatedToken *splunkapi.HECToken
+	updateCount  int
+	create       func() (*splunkapi.HECToken, error)
+	delete       func() error
+	get          func() (*splunkapi.HECToken, error)
+}
+
+func (m *mockSplunkClient) CreateToken(ctx context.Context, token splunkapi.HECToken) (*splunkapi.HECToken, error) {
+	m.createCalled = true
+	m.createdToken = token
+	return m.create()
+}
+func (m *mockSplunkClient) UpdateToken(ctx context.Context, token splunkapi.HECToken) (*splunkapi.HECToken, error) {
+	m.updatedToken = &token
+	m.updateCount += 1
+	return &token, nil
+}
+func (m *mockSplunkClient) DeleteToken(ctx context.Context, name string) error {
+	m.deleteCalled = true
+	m.deletedNames = append(m.deletedNames, name)
+	return m.delete()
+}
+func (m *mockSplunkClient) GetToken(ctx context.Context, name string) (*splunkapi.HECToken, error) {
+	m.getCalled = true
+	return m.get()
+}
+
+func createSuccess() (*splunkapi.HECToken, error) {
+	token := splunkapi.HECToken{
+		Spec: stv1alpha1.SplunkTokenSpec{
+			Name: "<internal-cluster-id>",
+		},
+		Value: testTokenValue,
+	}
+	return &token, nil
+}
+
+func createErrorIfCalled() (*splunkapi.HECToken, error) {
+	return nil, errors.New("should not call CreateToken")
+}
+
+func deleteSuccess() error {
+	return nil
+}
+
+func deleteErrorIfCalled() error {
+	return errors.New("should not call DeleteToken")
+}
+
+func testTokenSecret(data map[string][]byte) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       request.Namespace,
+			Name:            config.OwnedObjectName,
+			ResourceVersion: "1",
+			Labels:          map[string]string{config.ManagedSecretLabel: "true"},
+		},
+		Data: data,
+	}
+}
+
+func testSplunkToken() stv1alpha1.SplunkToken {
+	token := stv1alpha1.SplunkToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         request.Namespace,
+			Name:              request.Name,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: stv1alpha1.SplunkTokenSpec{
+			Name: "<internal-cluster-id>",
+		},
+	}
+	controllerutil.AddFinalizer(&token, config.TokenFinalizer)
+	return token
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatalf("error reading metric: %s", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func getTokenSecret(t *testing.T, cl client.Client) corev1.Secret {
+	t.Helper()
+	var hecSecret corev1.Secret
+	err := cl.Get(t.Context(),
+		types.NamespacedName{
+			Namespace: request.Namespace,
+			Name:      config.OwnedObjectName,
+		},
+		&hecSecret)
+	if err != nil {
+		t.Fatalf("error getting secret: %s", err)
+	}
+	return hecSecret
 }
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+// StatePath is the path the StateHandler is served from on the metrics server.
+const StatePath = "/debug/splunktokens"
+
+// StateHandler serves a read-only JSON summary of every managed SplunkToken for support cases.
+// It reads from the manager's cache and never contacts Splunk or exposes token values.
+type StateHandler struct {
+	Client       client.Reader
+	SplunkConfig config.General
+}
+
+// TokenState is the summary of a single SplunkToken served by the StateHandler.
+type TokenState struct {
+	Namespace    string     `json:"namespace"`
+	Name         string     `json:"name"`
+	TokenName    string     `json:"tokenName"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	RotatesAt    *time.Time `json:"rotatesAt,omitempty"`
+	Deleting     bool       `json:"deleting"`
+	SecretExists bool       `json:"secretExists"`
+}
+
+func (h *StateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	log := logf.FromContext(req.Context())
+
+	var tokens stv1alpha1.SplunkTokenList
+	if err := h.Client.List(req.Context(), &tokens); err != nil {
+		log.Error(err, "error listing SplunkTokens")
+		http.Error(w, "error listing SplunkTokens", http.StatusInternalServerError)
+		return
+	}
+
+	states := make([]TokenState, 0, len(tokens.Items))
+	for _, token := range tokens.Items {
+		state := TokenState{
+			Namespace: token.Namespace,
+			Name:      token.Name,
+			TokenName: token.Spec.Name,
+			CreatedAt: token.CreationTimestamp.Time,
+			Deleting:  !token.DeletionTimestamp.IsZero(),
+		}
+		if maxAge, _ := tokenMaxAge(h.SplunkConfig, &token); maxAge > 0 {
+			rotatesAt := rotationDeadline(h.SplunkConfig, &token)
+			state.RotatesAt = &rotatesAt
+		}
+
+		var secret corev1.Secret
+		secretKey := types.NamespacedName{Namespace: token.Namespace, Name: tokenSecretName(h.SplunkConfig, &token)}
+		err := h.Client.Get(req.Context(), secretKey, &secret)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "error retrieving token Secret", "namespace", token.Namespace)
+			http.Error(w, "error retrieving token Secret", http.StatusInternalServerError)
+			return
+		}
+		state.SecretExists = err == nil
+		states = append(states, state)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(states); err != nil {
+		log.Error(err, "error encoding SplunkToken state")
+	}
+}
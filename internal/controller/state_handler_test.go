@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+func TestStateHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("lists tokens without secret material", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+		hecSecret := testTokenSecret(map[string][]byte{
+			"outputs.conf": []byte("httpEventCollectorToken = " + testTokenValue),
+		})
+		otherToken := testSplunkToken()
+		otherToken.Namespace = "other-namespace"
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(&splunkToken, &hecSecret, &otherToken).
+			Build()
+
+		handler := StateHandler{
+			Client:       fakeClient,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, StatePath, nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200 but got %d", recorder.Code)
+		}
+		if strings.Contains(recorder.Body.String(), testTokenValue) {
+			t.Errorf("response contains the HEC token value: %s", recorder.Body)
+		}
+
+		var states []TokenState
+		if err := json.Unmarshal(recorder.Body.Bytes(), &states); err != nil {
+			t.Fatalf("error decoding response: %s", err)
+		}
+		if len(states) != 2 {
+			t.Fatalf("expected 2 token states but got %d", len(states))
+		}
+		got := map[string]TokenState{}
+		for _, state := range states {
+			got[state.Namespace] = state
+		}
+		if !got[request.Namespace].SecretExists {
+			t.Errorf("expected Secret to exist for %s", request.Namespace)
+		}
+		if got["other-namespace"].SecretExists {
+			t.Error("expected Secret not to exist for other-namespace")
+		}
+		state := got[request.Namespace]
+		if state.TokenName != splunkToken.Spec.Name {
+			t.Errorf("expected token name %s but got %s", splunkToken.Spec.Name, state.TokenName)
+		}
+		if state.RotatesAt == nil || !state.RotatesAt.Equal(state.CreatedAt.Add(time.Hour)) {
+			t.Errorf("expected rotation time one hour after %s but got %v", state.CreatedAt, state.RotatesAt)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// An Outcome is a reconcile result counted by the ActivitySummary.
+type Outcome string
+
+const (
+	OutcomeCreated Outcome = "created"
+	OutcomeRotated Outcome = "rotated"
+	OutcomeDeleted Outcome = "deleted"
+	OutcomeErrored Outcome = "errored"
+)
+
+// ActivitySummary counts reconcile outcomes and logs the totals once per Interval
+// as a heartbeat of fleet-wide activity. Counts are reset after each summary is logged.
+// A nil ActivitySummary discards everything it is given.
+type ActivitySummary struct {
+	Interval time.Duration
+
+	mu     sync.Mutex
+	counts map[Outcome]int
+}
+
+// Record counts a single reconcile outcome.
+func (s *ActivitySummary) Record(outcome Outcome) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = map[Outcome]int{}
+	}
+	s.counts[outcome]++
+}
+
+// Flush returns the counts recorded since the last Flush and resets them.
+func (s *ActivitySummary) Flush() map[Outcome]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := s.counts
+	s.counts = nil
+	return counts
+}
+
+// Start logs a summary every Interval until the context is cancelled.
+// It implements manager.Runnable so the summary can be added to the manager.
+func (s *ActivitySummary) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("summary")
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			counts := s.Flush()
+			log.Info("splunk token activity",
+				"interval", s.Interval,
+				string(OutcomeCreated), counts[OutcomeCreated],
+				string(OutcomeRotated), counts[OutcomeRotated],
+				string(OutcomeDeleted), counts[OutcomeDeleted],
+				string(OutcomeErrored), counts[OutcomeErrored],
+			)
+		}
+	}
+}
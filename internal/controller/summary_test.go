@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+func TestActivitySummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	t.Run("counts outcomes and resets on flush", func(t *testing.T) {
+		summary := ActivitySummary{}
+		summary.Record(OutcomeCreated)
+		summary.Record(OutcomeCreated)
+		summary.Record(OutcomeErrored)
+
+		counts := summary.Flush()
+		if counts[OutcomeCreated] != 2 || counts[OutcomeErrored] != 1 || counts[OutcomeRotated] != 0 {
+			t.Errorf("unexpected counts %v", counts)
+		}
+		if counts := summary.Flush(); len(counts) != 0 {
+			t.Errorf("expected counts to reset after flush but got %v", counts)
+		}
+	})
+
+	t.Run("nil summary discards outcomes", func(t *testing.T) {
+		var summary *ActivitySummary
+		summary.Record(OutcomeCreated)
+	})
+
+	t.Run("reconcile records created and errored outcomes", func(t *testing.T) {
+		splunkToken := testSplunkToken()
+
+		fakeClient := fakeclient.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+			WithRuntimeObjects(&splunkToken).
+			Build()
+
+		summary := &ActivitySummary{}
+		mockSplunk := mockSplunkClient{
+			create: func() (*splunkapi.HECToken, error) { return nil, errors.New("ACS unavailable") },
+			delete: deleteErrorIfCalled,
+		}
+		reconciler := SplunkTokenReconciler{
+			Client:       fakeClient,
+			Scheme:       scheme,
+			SplunkApi:    &mockSplunk,
+			SplunkConfig: config.General{TokenMaxAge: time.Hour},
+			Summary:      summary,
+		}
+
+		if _, err := reconciler.Reconcile(t.Context(), request); err == nil {
+			t.Error("expected error during reconcile but did not get one")
+		}
+		mockSplunk.create = createSuccess
+		if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+			t.Errorf("unexpected error during reconcile: %s", err)
+		}
+
+		counts := summary.Flush()
+		if counts[OutcomeErrored] != 1 || counts[OutcomeCreated] != 1 {
+			t.Errorf("expected one errored and one created outcome but got %v", counts)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
+)
+
+// TokenAgeSweep periodically reports the age of the oldest managed HEC token, relative to its
+// max age, through the OldestTokenAgeRatio metric so alerts can fire if rotation stops working.
+type TokenAgeSweep struct {
+	Client       client.Reader
+	SplunkConfig config.General
+	Interval     time.Duration
+	// Clock provides the current time. Defaults to the real clock when nil.
+	Clock clock.PassiveClock
+}
+
+// Sweep lists every SplunkToken and returns the largest ratio of a HEC token's age to its max age.
+// SplunkTokens that are being deleted, whose rotation is disabled, or whose namespace is not
+// managed are skipped.
+func (s *TokenAgeSweep) Sweep(ctx context.Context) (float64, error) {
+	var tokens stv1alpha1.SplunkTokenList
+	if err := s.Client.List(ctx, &tokens); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if s.Clock != nil {
+		now = s.Clock.Now()
+	}
+	var oldest float64
+	for _, token := range tokens.Items {
+		maxAge, _ := tokenMaxAge(s.SplunkConfig, &token)
+		if maxAge <= 0 || !token.DeletionTimestamp.IsZero() || !namespaceManaged(s.SplunkConfig, token.Namespace) {
+			continue
+		}
+		ratio := float64(now.Sub(tokenAgeStart(s.SplunkConfig, &token))) / float64(maxAge)
+		oldest = max(oldest, ratio)
+	}
+	return oldest, nil
+}
+
+// Start updates the OldestTokenAgeRatio metric every Interval until the context is cancelled.
+// It implements manager.Runnable so the sweep can be added to the manager.
+func (s *TokenAgeSweep) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("token-age")
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.update(ctx); err != nil {
+				log.Error(err, "error measuring HEC token age")
+			}
+		}
+	}
+}
+
+// update sets the OldestTokenAgeRatio metric from a new Sweep.
+func (s *TokenAgeSweep) update(ctx context.Context) error {
+	oldest, err := s.Sweep(ctx)
+	if err != nil {
+		return err
+	}
+	metrics.OldestTokenAgeRatio.Set(oldest)
+	return nil
+}
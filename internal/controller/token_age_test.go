@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
+)
+
+func TestTokenAgeSweep(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	newToken := func(name string, age time.Duration) *stv1alpha1.SplunkToken {
+		token := testSplunkToken()
+		token.Name = name
+		token.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		return &token
+	}
+
+	tests := []struct {
+		name      string
+		tokens    []runtime.Object
+		splunkCfg config.General
+		want      float64
+	}{
+		{
+			name:      "reports overdue token",
+			tokens:    []runtime.Object{newToken("fresh", time.Hour), newToken("overdue", 36*time.Hour)},
+			splunkCfg: config.General{TokenMaxAge: 24 * time.Hour},
+			want:      1.5,
+		},
+		{
+			name:      "reports oldest token within max age",
+			tokens:    []runtime.Object{newToken("fresh", time.Hour), newToken("older", 12*time.Hour)},
+			splunkCfg: config.General{TokenMaxAge: 24 * time.Hour},
+			want:      0.5,
+		},
+		{
+			name: "uses max age annotation",
+			tokens: func() []runtime.Object {
+				token := newToken("annotated", 12*time.Hour)
+				token.Annotations = map[string]string{config.MaxAgeAnnotation: "6h"}
+				return []runtime.Object{token}
+			}(),
+			splunkCfg: config.General{TokenMaxAge: 24 * time.Hour},
+			want:      2,
+		},
+		{
+			name:      "ignores tokens in unmanaged namespaces",
+			tokens:    []runtime.Object{newToken("overdue", 36*time.Hour)},
+			splunkCfg: config.General{TokenMaxAge: 24 * time.Hour, DeniedNamespaces: []string{request.Namespace}},
+			want:      0,
+		},
+		{
+			name:      "ignores tokens when rotation is disabled",
+			tokens:    []runtime.Object{newToken("overdue", 36*time.Hour)},
+			splunkCfg: config.General{},
+			want:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(tt.tokens...).
+				Build()
+			sweep := TokenAgeSweep{
+				Client:       fakeClient,
+				SplunkConfig: tt.splunkCfg,
+				Clock:        clocktesting.NewFakePassiveClock(now),
+			}
+
+			if err := sweep.update(t.Context()); err != nil {
+				t.Fatalf("unexpected error during sweep: %s", err)
+			}
+			var metric dto.Metric
+			if err := metrics.OldestTokenAgeRatio.Write(&metric); err != nil {
+				t.Fatalf("error reading metric: %s", err)
+			}
+			if got := metric.GetGauge().GetValue(); got != tt.want {
+				t.Errorf("expected oldest token age ratio %v but got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/splunk-token-operator/internal/metrics"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+const (
+	// defaultTokenQuotaWarningRatio is used when TokenQuota.WarningRatio is not set.
+	defaultTokenQuotaWarningRatio = 0.9
+	// defaultTokenQuotaInterval is used when TokenQuota.Interval is not set.
+	defaultTokenQuotaInterval = 10 * time.Minute
+)
+
+// TokenQuota periodically counts the HEC tokens on the Splunk instance and reports the count,
+// and its fraction of the instance's token quota, through the HECTokens and HECTokenQuotaRatio
+// metrics. The count is kept so reconciles can warn before creating a token near the quota
+// without listing every token themselves.
+type TokenQuota struct {
+	Lister splunkapi.TokenLister
+	// Quota is the number of HEC tokens the Splunk instance allows.
+	Quota int
+	// WarningRatio is the fraction of Quota at which creating a token records a warning event.
+	// Defaults to 0.9 when zero.
+	WarningRatio float64
+	// Interval is how often the tokens are counted. Defaults to 10 minutes when zero.
+	Interval time.Duration
+
+	mu    sync.Mutex
+	count int
+	// counted is set once a count has been taken, so nothing is reported before then.
+	counted bool
+}
+
+// Refresh lists the HEC tokens on the Splunk instance and updates the count and metrics.
+func (q *TokenQuota) Refresh(ctx context.Context) error {
+	tokens, err := q.Lister.ListTokens(ctx)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.count = len(tokens)
+	q.counted = true
+	q.mu.Unlock()
+
+	metrics.HECTokens.Set(float64(len(tokens)))
+	if q.Quota > 0 {
+		metrics.HECTokenQuotaRatio.Set(float64(len(tokens)) / float64(q.Quota))
+	}
+	return nil
+}
+
+// Start refreshes the token count immediately and then every Interval until the context is
+// cancelled. It implements manager.Runnable so the count can be added to the manager.
+func (q *TokenQuota) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("token-quota")
+	if err := q.Refresh(ctx); err != nil {
+		log.Error(err, "error counting HEC tokens")
+	}
+	interval := q.Interval
+	if interval <= 0 {
+		interval = defaultTokenQuotaInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := q.Refresh(ctx); err != nil {
+				log.Error(err, "error counting HEC tokens")
+			}
+		}
+	}
+}
+
+// nearLimit reports whether the last count reached WarningRatio of the Quota, along with the count.
+// It is safe to call on a nil TokenQuota, which is never near its limit.
+func (q *TokenQuota) nearLimit() (bool, int) {
+	if q == nil || q.Quota <= 0 {
+		return false, 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.counted {
+		return false, 0
+	}
+	ratio := q.WarningRatio
+	if ratio <= 0 {
+		ratio = defaultTokenQuotaWarningRatio
+	}
+	return float64(q.count) >= ratio*float64(q.Quota), q.count
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+// mockTokenLister returns count empty tokens.
+type mockTokenLister struct {
+	count int
+	calls int
+}
+
+func (m *mockTokenLister) ListTokens(ctx context.Context) ([]splunkapi.HECToken, error) {
+	m.calls += 1
+	return make([]splunkapi.HECToken, m.count), nil
+}
+
+func TestTokenQuotaRefresh(t *testing.T) {
+	quota := TokenQuota{Lister: &mockTokenLister{count: 45}, Quota: 50}
+	if near, _ := quota.nearLimit(); near {
+		t.Error("expected quota not to be near its limit before the tokens are counted")
+	}
+	if err := quota.Refresh(t.Context()); err != nil {
+		t.Fatalf("unexpected error counting tokens: %s", err)
+	}
+
+	var metric dto.Metric
+	if err := metrics.HECTokens.Write(&metric); err != nil {
+		t.Fatalf("error reading metric: %s", err)
+	}
+	if got := metric.GetGauge().GetValue(); got != 45 {
+		t.Errorf("expected 45 HEC tokens but got %v", got)
+	}
+	if err := metrics.HECTokenQuotaRatio.Write(&metric); err != nil {
+		t.Fatalf("error reading metric: %s", err)
+	}
+	if got := metric.GetGauge().GetValue(); got != 0.9 {
+		t.Errorf("expected quota ratio 0.9 but got %v", got)
+	}
+	if near, count := quota.nearLimit(); !near || count != 45 {
+		t.Errorf("expected quota to be near its limit with 45 tokens but got %t with %d", near, count)
+	}
+
+	quota.WarningRatio = 0.95
+	if near, _ := quota.nearLimit(); near {
+		t.Error("expected quota not to be near its limit below the warning ratio")
+	}
+}
+
+func TestReconcileTokenQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(stv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	tests := []struct {
+		name      string
+		count     int
+		wantEvent bool
+	}{
+		{name: "warns when creating token near quota", count: 98, wantEvent: true},
+		{name: "does not warn below warning ratio", count: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkToken := testSplunkToken()
+			fakeClient := fakeclient.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&stv1alpha1.SplunkToken{}).
+				WithRuntimeObjects(&splunkToken).
+				Build()
+
+			lister := mockTokenLister{count: tt.count}
+			quota := TokenQuota{Lister: &lister, Quota: 100}
+			if err := quota.Refresh(t.Context()); err != nil {
+				t.Fatalf("unexpected error counting tokens: %s", err)
+			}
+			mockSplunk := mockSplunkClient{create: createSuccess, delete: deleteErrorIfCalled}
+			recorder := record.NewFakeRecorder(5)
+			reconciler := SplunkTokenReconciler{
+				Client:       fakeClient,
+				Scheme:       scheme,
+				Recorder:     recorder,
+				SplunkApi:    &mockSplunk,
+				SplunkConfig: config.General{TokenMaxAge: time.Hour},
+				Quota:        &quota,
+			}
+
+			if _, err := reconciler.Reconcile(t.Context(), request); err != nil {
+				t.Fatalf("unexpected error during reconcile: %s", err)
+			}
+			if !mockSplunk.createCalled {
+				t.Error("expected HEC token to be created")
+			}
+			if lister.calls != 1 {
+				t.Errorf("expected reconcile not to list tokens but they were listed %d times", lister.calls)
+			}
+			var warned bool
+			for len(recorder.Events) > 0 {
+				if strings.Contains(<-recorder.Events, "TokenQuotaNearlyReached") {
+					warned = true
+				}
+			}
+			if warned != tt.wantEvent {
+				t.Errorf("expected TokenQuotaNearlyReached event %t but got %t", tt.wantEvent, warned)
+			}
+		})
+	}
+}
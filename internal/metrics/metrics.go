@@ -0,0 +1,81 @@
+// Package metrics defines the Prometheus metrics exported by the operator.
+// All metrics are registered with the controller-runtime registry so they are
+// served from the manager's metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// InvalidTokenValues counts HEC tokens returned by Splunk with an empty or malformed value.
+	InvalidTokenValues = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "splunk_token_invalid_value_total",
+		Help: "Number of HEC tokens returned by Splunk with an empty or malformed token value.",
+	})
+
+	// SecretOperations counts token Secret writes by operation (create, update, delete)
+	// and outcome (success, error).
+	SecretOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "splunk_token_secret_operations_total",
+		Help: "Number of token Secret operations performed by the operator, by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	// ACSRequestDuration observes the latency of requests to Splunk by operation
+	// (create, update, delete, get, list, list_indexes), including failed requests.
+	ACSRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "splunk_token_acs_request_duration_seconds",
+		Help:    "Latency of requests to the Splunk token management API, by operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// OrphanedTokens counts HEC tokens left in Splunk because the finalizer timeout expired
+	// before they could be deleted.
+	OrphanedTokens = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "splunk_token_orphaned_total",
+		Help: "Number of HEC tokens left in Splunk after the finalizer timeout expired before they could be deleted.",
+	})
+
+	// OldestTokenAgeRatio is the age of the oldest managed HEC token as a fraction of its max age.
+	// A value above 1 means rotation has fallen behind.
+	OldestTokenAgeRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "splunk_token_oldest_age_ratio",
+		Help: "Age of the oldest managed HEC token divided by its max age. Values above 1 mean rotation is overdue.",
+	})
+
+	// HECTokens is the number of HEC tokens on the Splunk instance, including tokens the operator
+	// does not manage, as of the last periodic count.
+	HECTokens = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "splunk_token_hec_tokens",
+		Help: "Number of HEC tokens on the Splunk instance as of the last count.",
+	})
+
+	// HECTokenQuotaRatio is HECTokens as a fraction of the configured token quota.
+	// A value of 1 means no more tokens can be created.
+	HECTokenQuotaRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "splunk_token_hec_token_quota_ratio",
+		Help: "Number of HEC tokens on the Splunk instance divided by the configured token quota.",
+	})
+)
+
+// RecordSecretOperation increments SecretOperations for the operation, using err to determine the outcome.
+func RecordSecretOperation(operation string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	SecretOperations.WithLabelValues(operation, outcome).Inc()
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		InvalidTokenValues,
+		SecretOperations,
+		ACSRequestDuration,
+		OldestTokenAgeRatio,
+		OrphanedTokens,
+		HECTokens,
+		HECTokenQuotaRatio,
+	)
+}
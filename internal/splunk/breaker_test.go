@@ -0,0 +1,113 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("fails fast after consecutive connection failures", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithCircuitBreaker(2, time.Minute)(testClient)
+
+		for range 2 {
+			err := testClient.DeleteToken(t.Context(), "bar")
+			if err == nil || errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("expected connection error but got %v", err)
+			}
+		}
+		if err := testClient.DeleteToken(t.Context(), "bar"); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("expected circuit breaker error but got %v", err)
+		}
+	})
+
+	t.Run("lets a probe through after cooldown and closes on success", func(t *testing.T) {
+		var serverCalls uint
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverCalls += 1
+			w.WriteHeader(http.StatusAccepted)
+			io.WriteString(w, "")
+		}))
+		defer splunkServer.Close()
+
+		now := time.Now()
+		testClient := createTestClient(splunkServer.URL)
+		WithCircuitBreaker(1, time.Minute)(testClient)
+		testClient.breaker.now = func() time.Time { return now }
+
+		// trip the breaker as though a request had failed to connect
+		testClient.breaker.record(errors.New("connection refused"))
+		if err := testClient.DeleteToken(t.Context(), "bar"); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected circuit breaker error but got %v", err)
+		}
+		if serverCalls != 0 {
+			t.Fatalf("expected no requests while breaker is open but got %d", serverCalls)
+		}
+
+		now = now.Add(2 * time.Minute)
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("expected probe request to succeed but got %v", err)
+		}
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Errorf("expected breaker to close after successful probe but got %v", err)
+		}
+		if serverCalls != 2 {
+			t.Errorf("expected 2 requests after breaker closed but got %d", serverCalls)
+		}
+	})
+
+	t.Run("keeps probing after a request is canceled waiting for its limits", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer splunkServer.Close()
+
+		now := time.Now()
+		testClient := createTestClient(splunkServer.URL)
+		WithCircuitBreaker(1, time.Minute)(testClient)
+		WithConcurrencyLimit(1)(testClient)
+		testClient.breaker.now = func() time.Time { return now }
+		testClient.breaker.record(errors.New("connection refused"))
+		now = now.Add(2 * time.Minute)
+
+		testClient.limits.inflight <- struct{}{}
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if err := testClient.DeleteToken(ctx, "bar"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled error while waiting for a slot but got %v", err)
+		}
+		<-testClient.limits.inflight
+
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Errorf("expected probe request to be let through but got %v", err)
+		}
+	})
+
+	t.Run("only lets one probe through while half-open", func(t *testing.T) {
+		now := time.Now()
+		breaker := newCircuitBreaker(1, time.Minute)
+		breaker.now = func() time.Time { return now }
+		breaker.record(errors.New("connection refused"))
+
+		now = now.Add(2 * time.Minute)
+		if !breaker.allow() {
+			t.Fatal("expected probe to be allowed after cooldown")
+		}
+		if breaker.allow() {
+			t.Error("expected second request to be rejected while probe is in flight")
+		}
+		breaker.record(errors.New("connection refused"))
+		if breaker.allow() {
+			t.Error("expected failed probe to reopen the breaker")
+		}
+	})
+}
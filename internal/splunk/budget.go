@@ -0,0 +1,33 @@
+package splunkapi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrRetryBudgetExhausted is returned when a request needs another retry but the retry budget of its
+// context has been spent.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted, not retrying request to Splunk")
+
+type retryBudgetKey struct{}
+
+// WithRetryBudget returns a context that allows at most retries retries in total across every
+// request made with it, or with contexts derived from it, so a single reconcile cannot hammer
+// Splunk however many calls it makes. Once the budget is spent, requests fail fast with their
+// last error instead of being retried.
+func WithRetryBudget(ctx context.Context, retries int) context.Context {
+	remaining := &atomic.Int64{}
+	remaining.Store(int64(retries))
+	return context.WithValue(ctx, retryBudgetKey{}, remaining)
+}
+
+// SpendRetry reports whether the retry budget of the context allows another retry, using up one
+// retry if it does. A context without a retry budget always allows retries.
+func SpendRetry(ctx context.Context) bool {
+	remaining, ok := ctx.Value(retryBudgetKey{}).(*atomic.Int64)
+	if !ok {
+		return true
+	}
+	return remaining.Add(-1) >= 0
+}
@@ -0,0 +1,65 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Run("fails fast once budget is spent", func(t *testing.T) {
+		var calls int
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls += 1
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code":"transient-error","message":"try again later"}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithRetryableErrorCodes([]string{"transient-error"}, 5)(testClient)
+		testClient.retry.backoff = 0
+		ctx := WithRetryBudget(t.Context(), 2)
+
+		if _, err := testClient.GetToken(ctx, "bar"); err == nil {
+			t.Error("expected error after retry budget was spent")
+		}
+		if calls != 3 {
+			t.Errorf("expected request and 2 retries but got %d calls", calls)
+		}
+		calls = 0
+		if err := testClient.DeleteToken(ctx, "bar"); err == nil {
+			t.Error("expected error after retry budget was spent")
+		}
+		if calls != 1 {
+			t.Errorf("expected request without retries once budget was spent but got %d calls", calls)
+		}
+	})
+
+	t.Run("stops delete consistency checks", func(t *testing.T) {
+		server := &consistencyServer{}
+		splunkServer := httptest.NewServer(server)
+		defer splunkServer.Close()
+		testClient := createTestClient(splunkServer.URL)
+		WithDeleteConsistencyWindow(time.Minute, time.Millisecond)(testClient)
+		testClient.recent.add("bar")
+
+		err := testClient.DeleteToken(WithRetryBudget(t.Context(), 2), "bar")
+		if !errors.Is(err, ErrRetryBudgetExhausted) {
+			t.Errorf("expected retry budget error but got %v", err)
+		}
+		if server.gets != 2 {
+			t.Errorf("expected 2 checks within retry budget but got %d", server.gets)
+		}
+	})
+
+	t.Run("allows retries without budget", func(t *testing.T) {
+		if !SpendRetry(t.Context()) {
+			t.Error("expected context without budget to allow retries")
+		}
+	})
+}
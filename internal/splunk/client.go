@@ -1,19 +1,27 @@
 // Package splunkapi defines a simple api for creating and deleting Splunk
 // HTTP Event Collector (HEC) tokens through Splunk's Admin Config Services
-// interface.
+// interface, or the REST API of Splunk Enterprise.
 package splunkapi
 
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"slices"
+	"strconv"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
 )
 
 const (
@@ -22,6 +30,15 @@ const (
 
 	missingSplunkError string = "missing Splunk instance name"
 	missingJWTError    string = "missing Splunk authentication token"
+
+	// defaultRequestIDHeader is the response header carrying the ACS request ID.
+	defaultRequestIDHeader string = "X-Request-Id"
+
+	// tagsField is the ACS field holding a token's tags.
+	tagsField string = "tags"
+
+	// defaultMaxErrorBodySize bounds how much of an error response body is read.
+	defaultMaxErrorBodySize int64 = 64 * 1024
 )
 
 // A Client contains the information necessary to connect to Splunk ACS for the
@@ -29,16 +46,72 @@ const (
 // does not make any assumptions and contains no information, and the NewClient
 // function should be used to create a working connection.
 type Client struct {
-	jwt    string
-	url    string
-	client http.Client
+	jwt        string
+	url        string
+	indexesURL string
+	client     http.Client
+	fieldNames FieldNames
+	breaker    *circuitBreaker
+	limits     requestLimits
+	retry      *codeRetry
+	headers    http.Header
+
+	maxErrorBodySize int64
+	metadataFields   []string
+	updateMethod     string
+	requestIDHeader  string
+	strictDecoding   bool
+	validateJWT      bool
+	indexes          *indexCache
+	recent           *recentTokens
+	maintenanceCodes []string
+	minTLSVersion    uint16
+	api              tokenAPI
+}
+
+// A tokenAPI formats token management requests and reads their responses for one of the
+// Splunk APIs that manage HEC tokens. The paths and payloads differ between ACS on
+// Splunk Cloud and the REST API of Splunk Enterprise.
+type tokenAPI interface {
+	// tokenURL returns the URL of the named token, or of all tokens when name is empty.
+	tokenURL(base, name string) (string, error)
+	// encodeToken returns the body and content type of a request creating or updating a token.
+	encodeToken(spec v1alpha1.SplunkTokenSpec, metadata, tags map[string]string, fieldNames FieldNames, create bool) ([]byte, string, error)
+	// decodeToken reads the token from the response to a GetToken request.
+	// In strict mode, unknown fields and data after the token are errors.
+	decodeToken(body io.Reader, strict bool) (*HECToken, error)
+	// decodeTokenList reads a page of tokens from the response to a ListTokens request.
+	decodeTokenList(body io.Reader) ([]HECToken, error)
+	// deletedStatus is the status code of a successful DeleteToken request.
+	deletedStatus() int
+}
+
+// acsAPI manages tokens through Splunk Cloud's Admin Config Services.
+type acsAPI struct{}
+
+// A ClientOption configures optional behavior of a Client.
+type ClientOption func(*Client)
+
+// FieldNames maps the JSON field names of a SplunkTokenSpec to the names
+// used in the ACS request body. Different versions of the ACS API may name
+// the same field differently (e.g. defaultIndex vs default_index).
+// Fields without an entry are sent using the name from defaultFieldNames,
+// or their SplunkTokenSpec name if they have no default.
+type FieldNames map[string]string
+
+// defaultFieldNames are the ACS names of spec fields whose JSON names differ.
+var defaultFieldNames = FieldNames{
+	"sourcetype": "defaultSourcetype",
 }
 
 // The TokenManager interface defines the necessary functions for interacting with Splunk HEC tokens.
-// For our purposes the manager only needs to create and delete tokens.
+// For our purposes the manager only needs to create, update, and delete tokens,
+// and read them back to verify they still exist.
 type TokenManager interface {
 	CreateToken(context.Context, HECToken) (*HECToken, error)
+	UpdateToken(context.Context, HECToken) (*HECToken, error)
 	DeleteToken(context.Context, string) error
+	GetToken(context.Context, string) (*HECToken, error)
 }
 
 // The HECToken struct defines the fields we need for HEC token management.
@@ -48,22 +121,167 @@ type TokenManager interface {
 type HECToken struct {
 	Spec  v1alpha1.SplunkTokenSpec
 	Value string `json:"token,omitempty"`
+	// Metadata holds additional fields for the CreateToken request, such as owner or environment.
+	// Fields the Client is not configured to send are ignored.
+	Metadata map[string]string `json:"-"`
+	// Tags are sent as an object in the ACS tags field when creating or updating a token,
+	// e.g. to attribute ingestion cost to a business unit. The Splunk Enterprise API has no tags.
+	Tags map[string]string `json:"-"`
+	// Details holds the attributes of a token read from Splunk that are not part of its spec.
+	// It is never sent when creating or updating a token.
+	Details TokenDetails `json:"-"`
+}
+
+// TokenDetails are the attributes ACS returns for a token beyond those in its SplunkTokenSpec.
+type TokenDetails struct {
+	DefaultHost   string
+	DefaultSource string
+	Disabled      bool
+	// UseACK reports whether indexer acknowledgement is enabled for the token.
+	UseACK        bool
+	CreatedBy     string
+	CreatedAt     time.Time
+	LastUpdatedBy string
+	LastUpdatedAt time.Time
 }
 
 type tokenResponse struct {
-	Data HECToken `json:"http-event-collector"`
+	Data acsToken `json:"http-event-collector"`
+}
+
+// acsToken is a token as returned by ACS.
+type acsToken struct {
+	Spec struct {
+		v1alpha1.SplunkTokenSpec
+		DefaultSourcetype string            `json:"defaultSourcetype"`
+		DefaultHost       string            `json:"defaultHost"`
+		DefaultSource     string            `json:"defaultSource"`
+		Disabled          bool              `json:"disabled"`
+		UseACK            bool              `json:"useAck"`
+		Tags              map[string]string `json:"tags"`
+	} `json:"spec"`
+	Token         string    `json:"token"`
+	CreatedBy     string    `json:"createdBy"`
+	CreatedAt     time.Time `json:"createdAt"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
 }
 
+var (
+	// ErrForbidden matches error responses with a 403 Forbidden status.
+	ErrForbidden = errors.New("forbidden by Splunk")
+	// ErrNotFound matches error responses with a 404 Not Found status.
+	ErrNotFound = errors.New("not found in Splunk")
+)
+
 type errorResponse struct {
 	Code    string
 	Message string
+	// Messages holds the errors returned by the Splunk Enterprise REST API.
+	Messages    []errorMessage
+	statusCode  int
+	requestID   string
+	maintenance bool
 }
 
-// NewClient creates a new Splunk Client using the provided instance name and JWT.
-func NewClient(splunkStack, jwt string) (*Client, error) {
-	if splunkStack == "" {
-		return nil, errors.New(missingSplunkError)
+type errorMessage struct {
+	Text string
+}
+
+// WithFieldNames sets the request body field names used when creating tokens.
+func WithFieldNames(names FieldNames) ClientOption {
+	return func(c *Client) {
+		c.fieldNames = names
 	}
+}
+
+// WithCircuitBreaker stops sending requests to Splunk for the cooldown period
+// after threshold consecutive connection failures. A threshold of zero disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *Client) {
+		if threshold > 0 {
+			c.breaker = newCircuitBreaker(threshold, cooldown)
+		}
+	}
+}
+
+// WithHeaders adds static headers to every request sent to Splunk,
+// such as tenant or API gateway headers. The Authorization and Content-Type
+// headers are set by the Client and cannot be overridden.
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		c.headers = http.Header{}
+		for name, value := range headers {
+			switch http.CanonicalHeaderKey(name) {
+			case "Authorization", "Content-Type":
+				continue
+			}
+			c.headers.Set(name, value)
+		}
+	}
+}
+
+// WithMaxErrorBodySize limits how many bytes of an error response body are read.
+// Values less than one use the default of 64KiB.
+func WithMaxErrorBodySize(size int64) ClientOption {
+	return func(c *Client) {
+		if size > 0 {
+			c.maxErrorBodySize = size
+		}
+	}
+}
+
+// WithMetadataFields sets which HECToken Metadata fields are sent when creating tokens.
+// Only fields supported by the ACS API should be listed; other metadata is ignored.
+func WithMetadataFields(fields []string) ClientOption {
+	return func(c *Client) {
+		c.metadataFields = fields
+	}
+}
+
+// WithUpdateMethod sets the HTTP method used by UpdateToken, since ACS versions
+// differ between PUT and PATCH. An empty method uses the default of PUT.
+func WithUpdateMethod(method string) ClientOption {
+	return func(c *Client) {
+		if method != "" {
+			c.updateMethod = method
+		}
+	}
+}
+
+// WithRequestIDHeader sets the response header that carries the ACS request ID,
+// which is included in errors and debug logs for correlation with Splunk support.
+// An empty name uses the default of X-Request-Id.
+func WithRequestIDHeader(name string) ClientOption {
+	return func(c *Client) {
+		if name != "" {
+			c.requestIDHeader = name
+		}
+	}
+}
+
+// WithStrictDecoding makes the Client reject ACS token responses with fields it does not know
+// or with data after the token, so changes to the API surface as errors instead of being
+// silently ignored. Responses are decoded leniently by default. Index lists and responses of
+// the Splunk Enterprise API carry many fields the Client does not use and are always lenient.
+func WithStrictDecoding(strict bool) ClientOption {
+	return func(c *Client) {
+		c.strictDecoding = strict
+	}
+}
+
+// WithRequestTimeout limits how long a single request to Splunk may take, including reading
+// its response. Callers' contexts still bound the total time of operations that make several
+// requests. A timeout of zero means no limit.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.client.Timeout = timeout
+	}
+}
+
+// NewClient creates a new Splunk Client using the provided instance name and JWT.
+// The instance name may be empty with WithEnterpriseAPI.
+func NewClient(splunkStack, jwt string, opts ...ClientOption) (*Client, error) {
 	if jwt == "" {
 		return nil, errors.New(missingJWTError)
 	}
@@ -72,11 +290,34 @@ func NewClient(splunkStack, jwt string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		jwt:    jwt,
-		url:    fullUrl,
-		client: http.Client{},
-	}, nil
+	indexesURL, err := url.JoinPath(acsHostname, splunkStack, indexesPath)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		jwt:              jwt,
+		url:              fullUrl,
+		indexesURL:       indexesURL,
+		client:           http.Client{},
+		maxErrorBodySize: defaultMaxErrorBodySize,
+		updateMethod:     http.MethodPut,
+		requestIDHeader:  defaultRequestIDHeader,
+		minTLSVersion:    tls.VersionTLS12,
+		api:              acsAPI{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if _, isACS := c.api.(acsAPI); isACS && splunkStack == "" {
+		return nil, errors.New(missingSplunkError)
+	}
+	c.client.Transport = newTransport(c.minTLSVersion)
+	if c.validateJWT {
+		if err := checkJWT(jwt); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 // CreateToken takes a HECToken spec and creates a token on the Splunk instance.
@@ -85,72 +326,157 @@ func (c *Client) CreateToken(ctx context.Context, token HECToken) (*HECToken, er
 	if token.Spec.DefaultIndex != "" && !slices.Contains(token.Spec.AllowedIndexes, token.Spec.DefaultIndex) {
 		token.Spec.AllowedIndexes = append(token.Spec.AllowedIndexes, token.Spec.DefaultIndex)
 	}
-	payload, err := json.Marshal(token.Spec)
+	if c.indexes != nil {
+		if err := c.validateIndexes(ctx, token.Spec.AllowedIndexes); err != nil {
+			return nil, err
+		}
+	}
+	metadata := maps.Clone(token.Metadata)
+	maps.DeleteFunc(metadata, func(field, _ string) bool {
+		return !slices.Contains(c.metadataFields, field)
+	})
+	payload, contentType, err := c.api.encodeToken(token.Spec, metadata, token.Tags, c.fieldNames, true)
+	if err != nil {
+		return nil, err
+	}
+	createURL, err := c.api.tokenURL(c.url, "")
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, createURL, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 
-	res, err := c.client.Do(req)
+	res, err := c.do(req, "create", token.Spec.Name)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	decoder := json.NewDecoder(res.Body)
 	// skip error handling on 409 and retrieve existing token
 	if res.StatusCode >= 400 && res.StatusCode != http.StatusConflict {
-		response := &errorResponse{}
-		if err := decoder.Decode(response); err != nil {
-			return nil, err
-		}
-		return nil, response
+		return nil, c.decodeError(res)
 	}
+	c.recent.add(token.Spec.Name)
 
-	return c.getToken(ctx, token.Spec.Name)
+	return c.GetToken(ctx, token.Spec.Name)
+}
+
+// UpdateToken changes the settings of an existing token to match the HECToken spec.
+// The return value is the updated token as stored on the Splunk instance.
+func (c *Client) UpdateToken(ctx context.Context, token HECToken) (*HECToken, error) {
+	tokenUri, err := c.api.tokenURL(c.url, token.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	if token.Spec.DefaultIndex != "" && !slices.Contains(token.Spec.AllowedIndexes, token.Spec.DefaultIndex) {
+		token.Spec.AllowedIndexes = append(token.Spec.AllowedIndexes, token.Spec.DefaultIndex)
+	}
+	payload, contentType, err := c.api.encodeToken(token.Spec, nil, token.Tags, c.fieldNames, false)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.updateMethod, tokenUri, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
+	req.Header.Add("Content-Type", contentType)
+
+	res, err := c.do(req, "update", token.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return nil, c.decodeError(res)
+	}
+	return c.GetToken(ctx, token.Spec.Name)
 }
 
 // DeleteToken deletes the named token, returning any error from the Splunk server.
+// A token that does not exist is already deleted, unless the Client created it within its
+// delete consistency window, in which case it is checked until it shows up or the window passes.
 func (c *Client) DeleteToken(ctx context.Context, name string) error {
-	tokenUri, err := url.JoinPath(c.url, name)
+	for {
+		deleted, err := c.deleteToken(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			// a token created moments ago may not be found yet, so check it is really gone
+			exists, err := c.awaitToken(ctx, name)
+			if err != nil {
+				return err
+			} else if exists {
+				continue
+			}
+		}
+		c.recent.remove(name)
+		return nil
+	}
+}
+
+// deleteToken sends a single request to delete the named token.
+// It reports false if Splunk responds that the token does not exist.
+func (c *Client) deleteToken(ctx context.Context, name string) (bool, error) {
+	tokenUri, err := c.api.tokenURL(c.url, name)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, tokenUri, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
-	res, err := c.client.Do(req)
+	res, err := c.do(req, "delete", name)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode == http.StatusNotFound {
 		// HEC token doesn't exist so we're done here
-		return nil
-	} else if res.StatusCode != http.StatusAccepted {
-		decoder := json.NewDecoder(res.Body)
-		response := &errorResponse{}
-		if err := decoder.Decode(response); err != nil {
-			return err
-		}
-		return response
+		return false, nil
+	} else if res.StatusCode != c.api.deletedStatus() {
+		return false, c.decodeError(res)
+	}
+	return true, nil
+}
+
+// CheckAccess verifies the Client's JWT can list HEC tokens on the Splunk instance.
+// A JWT without permission to manage HEC tokens returns an error for which IsForbidden is true.
+func (c *Client) CheckAccess(ctx context.Context) error {
+	listURL, err := c.api.tokenURL(c.url, "")
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
+	res, err := c.do(req, "list", "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return c.decodeError(res)
 	}
 	return nil
 }
 
-func (c *Client) getToken(ctx context.Context, name string) (*HECToken, error) {
-	getURL, err := url.JoinPath(c.url, name)
+// GetToken retrieves the named token. If the token does not exist the error satisfies IsNotFound.
+func (c *Client) GetToken(ctx context.Context, name string) (*HECToken, error) {
+	getURL, err := c.api.tokenURL(c.url, name)
 	if err != nil {
 		return nil, err
 	}
@@ -161,27 +487,233 @@ func (c *Client) getToken(ctx context.Context, name string) (*HECToken, error) {
 	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
 	request.Header.Add("Content-Type", "application/json")
 
-	res, err := c.client.Do(request)
+	res, err := c.do(request, "get", name)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
-	decoder := json.NewDecoder(res.Body)
-
 	if res.StatusCode >= 400 {
-		response := &errorResponse{}
-		if err := decoder.Decode(response); err != nil {
+		return nil, c.decodeError(res)
+	}
+	return c.api.decodeToken(res.Body, c.strictDecoding)
+}
+
+// do sends the request for the named operation to Splunk, failing fast while the circuit breaker is open.
+// The latency of each request is recorded in the ACSRequestDuration metric, and logged at debug level
+// along with the ACS request ID and the name of the token, if any. Requests failing with a
+// retryable error code are retried after a backoff.
+func (c *Client) do(req *http.Request, operation, tokenName string) (*http.Response, error) {
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	for attempt := 0; ; attempt++ {
+		res, err := c.send(req, operation, tokenName)
+		if err != nil || !c.shouldRetry(req, res, attempt) {
+			return res, err
+		}
+		if !SpendRetry(req.Context()) {
+			logf.FromContext(req.Context()).Info("retry budget exhausted, not retrying ACS request",
+				"operation", operation, "token", tokenName, "status", res.StatusCode)
+			return res, nil
+		}
+		res.Body.Close()
+		logf.FromContext(req.Context()).Info("retrying ACS request after transient error",
+			"operation", operation, "token", tokenName, "status", res.StatusCode, "attempt", attempt+1)
+		if err := c.waitToRetry(req, attempt); err != nil {
 			return nil, err
 		}
-		return nil, response
 	}
-	token := &tokenResponse{}
-	if err := decoder.Decode(token); err != nil {
+}
+
+// send sends a single request to Splunk, recording its duration and outcome.
+func (c *Client) send(req *http.Request, operation, tokenName string) (*http.Response, error) {
+	// wait for the limits first, so a probe let through by the breaker is always recorded
+	release, err := c.limits.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if c.breaker != nil && !c.breaker.allow() {
+		release()
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
+	res, err := c.client.Do(req)
+	release()
+	duration := time.Since(start)
+	metrics.ACSRequestDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if c.breaker != nil {
+		c.breaker.record(err)
+	}
+	if err != nil {
 		return nil, err
 	}
-	return &token.Data, nil
+	logf.FromContext(req.Context()).V(1).Info("received ACS response",
+		"operation", operation, "token", tokenName, "method", req.Method, "status", res.StatusCode,
+		"duration", duration, "requestID", res.Header.Get(c.requestIDHeader))
+	return res, nil
+}
+
+func (acsAPI) tokenURL(base, name string) (string, error) {
+	if name == "" {
+		return base, nil
+	}
+	return url.JoinPath(base, name)
+}
+
+// encodeToken always sends the disabled field when updating, since it is omitted from the spec
+// when false and the token would otherwise stay disabled after being enabled again.
+func (acsAPI) encodeToken(spec v1alpha1.SplunkTokenSpec, metadata, tags map[string]string, fieldNames FieldNames, create bool) ([]byte, string, error) {
+	payload, err := fieldNames.marshal(spec, metadata, tags)
+	if err != nil || create {
+		return payload, "application/json", err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, "", err
+	}
+	fields["disabled"] = json.RawMessage(strconv.FormatBool(spec.Disabled))
+	payload, err = json.Marshal(fields)
+	return payload, "application/json", err
+}
+
+func (acsAPI) decodeToken(body io.Reader, strict bool) (*HECToken, error) {
+	response := &tokenResponse{}
+	if err := decodeJSON(body, response, strict); err != nil {
+		return nil, err
+	}
+	return response.Data.hecToken(), nil
+}
+
+// decodeJSON decodes a single JSON value from body into v. In strict mode, fields that v
+// does not define and any data after the value are errors rather than silently ignored.
+func decodeJSON(body io.Reader, v any, strict bool) error {
+	decoder := json.NewDecoder(body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if strict {
+		if _, err := decoder.Token(); err != io.EOF {
+			return errors.New("unexpected data after JSON response")
+		}
+	}
+	return nil
+}
+
+// hecToken converts the ACS representation of a token, which names the sourcetype defaultSourcetype.
+func (t *acsToken) hecToken() *HECToken {
+	spec := t.Spec.SplunkTokenSpec
+	if spec.Sourcetype == "" {
+		spec.Sourcetype = t.Spec.DefaultSourcetype
+	}
+	spec.Disabled = t.Spec.Disabled
+	return &HECToken{
+		Spec:  spec,
+		Value: t.Token,
+		Tags:  t.Spec.Tags,
+		Details: TokenDetails{
+			DefaultHost:   t.Spec.DefaultHost,
+			DefaultSource: t.Spec.DefaultSource,
+			Disabled:      t.Spec.Disabled,
+			UseACK:        t.Spec.UseACK,
+			CreatedBy:     t.CreatedBy,
+			CreatedAt:     t.CreatedAt,
+			LastUpdatedBy: t.LastUpdatedBy,
+			LastUpdatedAt: t.LastUpdatedAt,
+		},
+	}
+}
+
+func (acsAPI) deletedStatus() int {
+	return http.StatusAccepted
+}
+
+// marshal encodes the spec, tags, and metadata as a request body, renaming any mapped spec fields.
+// Metadata cannot override a spec field or the tags.
+func (f FieldNames) marshal(spec v1alpha1.SplunkTokenSpec, metadata, tags map[string]string) ([]byte, error) {
+	payload, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	renamed := make(map[string]json.RawMessage, len(fields))
+	for specName, value := range fields {
+		wireName := f[specName]
+		if wireName == "" {
+			wireName = defaultFieldNames[specName]
+		}
+		if wireName == "" {
+			wireName = specName
+		}
+		renamed[wireName] = value
+	}
+	if len(tags) > 0 {
+		encoded, err := json.Marshal(tags)
+		if err != nil {
+			return nil, err
+		}
+		renamed[tagsField] = encoded
+	}
+	for field, value := range metadata {
+		if _, exists := renamed[field]; exists {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		renamed[field] = encoded
+	}
+	return json.Marshal(renamed)
+}
+
+// decodeError reads an ACS error response, reading no more than maxErrorBodySize bytes of the body.
+// If the body is truncated or cannot be decoded the HTTP status is used as the message
+// so the status code is not lost.
+func (c *Client) decodeError(res *http.Response) error {
+	response := &errorResponse{statusCode: res.StatusCode, requestID: res.Header.Get(c.requestIDHeader)}
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.maxErrorBodySize))
+	if err != nil || json.Unmarshal(body, response) != nil {
+		response.Message = res.Status
+	} else if response.Message == "" && len(response.Messages) > 0 {
+		response.Message = response.Messages[0].Text
+	}
+	response.maintenance = c.isMaintenanceCode(response.Code)
+	return response
+}
+
+// IsForbidden reports whether err is a 403 Forbidden response from Splunk,
+// which means the authentication token lacks permission to manage HEC tokens.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsNotFound reports whether err is a 404 Not Found response from Splunk.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// Is allows errors.Is to match error responses against ErrForbidden, ErrNotFound and ErrMaintenance.
+func (e *errorResponse) Is(target error) bool {
+	switch target {
+	case ErrMaintenance:
+		return e.maintenance
+	case ErrForbidden:
+		return e.statusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.statusCode == http.StatusNotFound
+	}
+	return false
 }
 
 func (e *errorResponse) Error() string {
+	if e.requestID != "" {
+		return fmt.Sprintf("received error response %s: %s (request ID %s)", e.Code, e.Message, e.requestID)
+	}
 	return fmt.Sprintf("received error response %s: %s", e.Code, e.Message)
 }
@@ -2,14 +2,21 @@
 package splunkapi
 
 import (
+	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 
 	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/internal/metrics"
 )
 
 func TestCreateClient(t *testing.T) {
@@ -41,6 +48,16 @@ func TestCreateClient(t *testing.T) {
 		}
 	})
 
+	t.Run("accepts missing stack with Enterprise API", func(t *testing.T) {
+		got, err := NewClient("", "foo", WithEnterpriseAPI("https://splunk.example.com:8089"))
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if want := "https://splunk.example.com:8089/" + enterpriseTokenPath; got.url != want {
+			t.Errorf("expected url %s but got %s", want, got.url)
+		}
+	})
+
 	t.Run("returns error if no auth token is provided", func(t *testing.T) {
 		_, err := NewClient("mock_splunk", "")
 		if err == nil {
@@ -50,6 +67,39 @@ func TestCreateClient(t *testing.T) {
 			t.Errorf("expected error for missing auth token but got %v", err)
 		}
 	})
+
+	t.Run("validates JWT format when configured", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+		claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"operator"}`))
+		wellFormed := header + "." + claims + ".c2lnbmF0dXJl"
+
+		tests := []struct {
+			name    string
+			jwt     string
+			wantErr bool
+		}{
+			{name: "well-formed JWT", jwt: wellFormed},
+			{name: "opaque token", jwt: "foo", wantErr: true},
+			{name: "missing signature", jwt: header + "." + claims, wantErr: true},
+			{name: "empty segment", jwt: header + ".." + "c2lnbmF0dXJl", wantErr: true},
+			{name: "invalid base64", jwt: header + ".not*base64.c2lnbmF0dXJl", wantErr: true},
+			{name: "header is not JSON", jwt: "bm90IGpzb24." + claims + ".c2lnbmF0dXJl", wantErr: true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := NewClient("mock_splunk", tt.jwt, WithJWTValidation(true))
+				if tt.wantErr && !errors.Is(err, ErrMalformedJWT) {
+					t.Errorf("expected malformed JWT error but got %v", err)
+				} else if !tt.wantErr && err != nil {
+					t.Errorf("got unexpected error: %s", err)
+				}
+			})
+		}
+
+		if _, err := NewClient("mock_splunk", "foo", WithJWTValidation(false)); err != nil {
+			t.Errorf("expected opaque token to be accepted without validation but got %v", err)
+		}
+	})
 }
 
 func TestCreateToken(t *testing.T) {
@@ -150,6 +200,36 @@ func TestCreateToken(t *testing.T) {
 		}
 	})
 
+	t.Run("sends description in request payload", func(t *testing.T) {
+		wantBody := `{"description":"managed by splunk-token-operator for cluster bar","name":"bar"}`
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("got unexpected error: %s", err)
+				}
+				if string(body) != wantBody {
+					t.Errorf("expected request payload '%s' but got '%s'", wantBody, body)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}
+			io.WriteString(w, `{"http-event-collector":{"spec":{"name":"bar"}}}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+
+		_, err := testClient.CreateToken(t.Context(), HECToken{
+			Spec: v1alpha1.SplunkTokenSpec{
+				Name:        "bar",
+				Description: "managed by splunk-token-operator for cluster bar",
+			},
+		})
+		if err != nil {
+			t.Errorf("error creating token: %s", err)
+		}
+	})
+
 	t.Run("creates with default and allowed indexes", func(t *testing.T) {
 		var (
 			wantName    = "bar"
@@ -198,6 +278,136 @@ func TestCreateToken(t *testing.T) {
 			t.Errorf("expected AllowedIndexes %v but got %v", wantIndexes, token.Spec.AllowedIndexes)
 		}
 	})
+	t.Run("uses configured field names in request body", func(t *testing.T) {
+		wantBody := `{"allowed_indexes":["other_index","audit_index"],"default_index":"audit_index","name":"bar"}`
+		var gotBody string
+
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("got unexpected error: %s", err)
+				}
+				gotBody = string(body)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithFieldNames(FieldNames{
+			"defaultIndex":   "default_index",
+			"allowedIndexes": "allowed_indexes",
+		})(testClient)
+
+		testClient.CreateToken(t.Context(),
+			HECToken{
+				Spec: v1alpha1.SplunkTokenSpec{
+					Name:           "bar",
+					DefaultIndex:   "audit_index",
+					AllowedIndexes: []string{"other_index"},
+				},
+			},
+		)
+		if gotBody != wantBody {
+			t.Errorf("expected request payload '%s' but got '%s'", wantBody, gotBody)
+		}
+	})
+
+	t.Run("sends only supported metadata fields", func(t *testing.T) {
+		wantBody := `{"environment":"staging","name":"bar","owner":"team-a"}`
+		var gotBody string
+
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithMetadataFields([]string{"owner", "environment", "name"})(testClient)
+
+		testClient.CreateToken(t.Context(),
+			HECToken{
+				Spec: v1alpha1.SplunkTokenSpec{
+					Name: "bar",
+				},
+				Metadata: map[string]string{
+					"owner":       "team-a",
+					"environment": "staging",
+					"name":        "overridden",
+					"unsupported": "ignored",
+				},
+			},
+		)
+		if gotBody != wantBody {
+			t.Errorf("expected request payload '%s' but got '%s'", wantBody, gotBody)
+		}
+	})
+
+	t.Run("sends tags in request payload", func(t *testing.T) {
+		wantBody := `{"name":"bar","owner":"team-a","tags":{"businessUnit":"security","costCenter":"1234"}}`
+		var gotBody string
+
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithMetadataFields([]string{"owner", "tags"})(testClient)
+
+		testClient.CreateToken(t.Context(),
+			HECToken{
+				Spec: v1alpha1.SplunkTokenSpec{
+					Name: "bar",
+				},
+				Metadata: map[string]string{
+					"owner": "team-a",
+					"tags":  "overridden",
+				},
+				Tags: map[string]string{
+					"businessUnit": "security",
+					"costCenter":   "1234",
+				},
+			},
+		)
+		if gotBody != wantBody {
+			t.Errorf("expected request payload '%s' but got '%s'", wantBody, gotBody)
+		}
+	})
+
+	t.Run("sends sourcetype using its ACS field name", func(t *testing.T) {
+		wantBody := `{"defaultSourcetype":"openshift:hcp","name":"bar"}`
+		var gotBody string
+
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+
+		testClient.CreateToken(t.Context(),
+			HECToken{
+				Spec: v1alpha1.SplunkTokenSpec{
+					Name:       "bar",
+					Sourcetype: "openshift:hcp",
+				},
+			},
+		)
+		if gotBody != wantBody {
+			t.Errorf("expected request payload '%s' but got '%s'", wantBody, gotBody)
+		}
+	})
+
 	t.Run("handles errors", func(t *testing.T) {
 		wantError := "received error response 400-oh-no-it-broke: halt and catch fire"
 
@@ -295,11 +505,407 @@ func TestDeleteToken(t *testing.T) {
 			t.Errorf("did not receive expected error message, got %s", err)
 		}
 	})
+
+	t.Run("identifies forbidden errors", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "not json")
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+
+		err := testClient.DeleteToken(t.Context(), "bar")
+		if !IsForbidden(err) {
+			t.Errorf("expected forbidden error but got %v", err)
+		}
+		if IsForbidden(errors.New("403")) {
+			t.Error("expected plain error not to be forbidden")
+		}
+	})
+}
+
+func TestUpdateToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		wantMethod string
+	}{
+		{name: "uses PUT by default", wantMethod: http.MethodPut},
+		{name: "uses configured method", method: http.MethodPatch, wantMethod: http.MethodPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantPath := "/mock_splunk/adminconfig/v2/inputs/http-event-collectors/bar"
+			var gotMethod string
+
+			splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					io.WriteString(w, `{"http-event-collector":{"spec":{"name":"bar","defaultIndex":"main"}}}`)
+					return
+				}
+				gotMethod = r.Method
+				if r.URL.Path != wantPath {
+					t.Errorf("expected request to %s but got %s", wantPath, r.URL.Path)
+				}
+				// an enabled token must be sent as enabled so a disabled token is enabled again
+				body, _ := io.ReadAll(r.Body)
+				if wantBody := `{"allowedIndexes":["main"],"defaultIndex":"main","disabled":false,"name":"bar"}`; string(body) != wantBody {
+					t.Errorf("expected request payload '%s' but got '%s'", wantBody, body)
+				}
+			}))
+			defer splunkServer.Close()
+
+			testClient := createTestClient(splunkServer.URL)
+			WithUpdateMethod(tt.method)(testClient)
+
+			updated, err := testClient.UpdateToken(t.Context(), HECToken{
+				Spec: v1alpha1.SplunkTokenSpec{Name: "bar", DefaultIndex: "main"},
+			})
+			if err != nil {
+				t.Fatalf("got unexpected error %s", err)
+			}
+			if gotMethod != tt.wantMethod {
+				t.Errorf("expected %s request but got %s", tt.wantMethod, gotMethod)
+			}
+			if updated.Spec.DefaultIndex != "main" {
+				t.Errorf("expected updated default index main but got %s", updated.Spec.DefaultIndex)
+			}
+		})
+	}
+}
+
+func TestGetToken(t *testing.T) {
+	t.Run("decodes the full ACS response", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, `{"http-event-collector":{
+				"spec":{"name":"bar","defaultIndex":"main","allowedIndexes":["main"],"defaultSourcetype":"openshift",
+					"defaultHost":"host","defaultSource":"source","disabled":true,"useAck":true},
+				"token":"UUID-VALUE",
+				"createdBy":"sre@example.com","createdAt":"2025-01-01T00:00:00Z",
+				"lastUpdatedBy":"operator@example.com","lastUpdatedAt":"2025-02-01T00:00:00Z"}}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		got, err := testClient.GetToken(t.Context(), "bar")
+		if err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		wantSpec := v1alpha1.SplunkTokenSpec{
+			Name:           "bar",
+			DefaultIndex:   "main",
+			AllowedIndexes: []string{"main"},
+			Sourcetype:     "openshift",
+			Disabled:       true,
+		}
+		if !reflect.DeepEqual(got.Spec, wantSpec) {
+			t.Errorf("expected spec %+v but got %+v", wantSpec, got.Spec)
+		}
+		if got.Value != "UUID-VALUE" {
+			t.Errorf("expected token value UUID-VALUE but got %s", got.Value)
+		}
+		wantDetails := TokenDetails{
+			DefaultHost:   "host",
+			DefaultSource: "source",
+			Disabled:      true,
+			UseACK:        true,
+			CreatedBy:     "sre@example.com",
+			CreatedAt:     time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			LastUpdatedBy: "operator@example.com",
+			LastUpdatedAt: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if !reflect.DeepEqual(got.Details, wantDetails) {
+			t.Errorf("expected details %+v but got %+v", wantDetails, got.Details)
+		}
+	})
+
+	t.Run("reports missing tokens as not found", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"code":"404-object-not-found","message":"not found"}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		_, err := testClient.GetToken(t.Context(), "bar")
+		if !IsNotFound(err) {
+			t.Errorf("expected not found error but got %v", err)
+		}
+		if IsForbidden(err) {
+			t.Error("expected not found error not to be forbidden")
+		}
+	})
+}
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		strict     bool
+		wantErr    bool
+		wantDetail string
+	}{
+		{
+			name:     "ignores unknown fields by default",
+			response: `{"http-event-collector":{"spec":{"name":"bar","newSetting":true},"token":"UUID-VALUE"}}`,
+		},
+		{
+			name:     "ignores trailing data by default",
+			response: `{"http-event-collector":{"spec":{"name":"bar"},"token":"UUID-VALUE"}} {"unexpected":1}`,
+		},
+		{
+			name:     "accepts known fields in strict mode",
+			response: `{"http-event-collector":{"spec":{"name":"bar","defaultIndex":"main"},"token":"UUID-VALUE"}}` + "\n",
+			strict:   true,
+		},
+		{
+			name:       "rejects unknown fields in strict mode",
+			response:   `{"http-event-collector":{"spec":{"name":"bar","newSetting":true},"token":"UUID-VALUE"}}`,
+			strict:     true,
+			wantErr:    true,
+			wantDetail: "newSetting",
+		},
+		{
+			name:       "rejects trailing data in strict mode",
+			response:   `{"http-event-collector":{"spec":{"name":"bar"},"token":"UUID-VALUE"}} {"unexpected":1}`,
+			strict:     true,
+			wantErr:    true,
+			wantDetail: "unexpected data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.response)
+			}))
+			defer splunkServer.Close()
+
+			testClient := createTestClient(splunkServer.URL)
+			WithStrictDecoding(tt.strict)(testClient)
+
+			token, err := testClient.GetToken(t.Context(), "bar")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.wantDetail) {
+					t.Errorf("expected error about %s but got %v", tt.wantDetail, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %s", err)
+			}
+			if token.Value != "UUID-VALUE" {
+				t.Errorf("expected token value UUID-VALUE but got %s", token.Value)
+			}
+		})
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	t.Run("succeeds when tokens can be listed", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("expected %s request but got %s", http.MethodGet, r.Method)
+			}
+			if r.URL.Path != "/mock_splunk/adminconfig/v2/inputs/http-event-collectors" {
+				t.Errorf("unexpected request path %s", r.URL.Path)
+			}
+			io.WriteString(w, `{"http-event-collectors":[]}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		if err := testClient.CheckAccess(t.Context()); err != nil {
+			t.Errorf("got unexpected error %s", err)
+		}
+	})
+
+	t.Run("reports forbidden when token lacks scope", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"code":"403-forbidden","message":"insufficient permissions"}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		err := testClient.CheckAccess(t.Context())
+		if !IsForbidden(err) {
+			t.Errorf("expected forbidden error but got %v", err)
+		}
+	})
+}
+
+func TestRequestHeaders(t *testing.T) {
+	t.Run("adds configured headers to all requests without overriding client headers", func(t *testing.T) {
+		var requests uint
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests += 1
+			if got := r.Header.Get("X-Tenant-Id"); got != "tenant" {
+				t.Errorf("expected header X-Tenant-Id with value 'tenant' on %s request but got '%s'", r.Method, got)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer foo" {
+				t.Errorf("expected header Authorization with value 'Bearer foo' on %s request but got '%s'", r.Method, got)
+			}
+			if got := r.Header.Get("Content-Type"); r.Method != http.MethodDelete && got != "application/json" {
+				t.Errorf("expected header Content-Type with value 'application/json' on %s request but got '%s'", r.Method, got)
+			}
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithHeaders(map[string]string{
+			"X-Tenant-Id":   "tenant",
+			"authorization": "Bearer overridden",
+			"Content-Type":  "text/plain",
+		})(testClient)
+
+		testClient.CreateToken(t.Context(), HECToken{Spec: v1alpha1.SplunkTokenSpec{Name: "bar"}})
+		testClient.DeleteToken(t.Context(), "bar")
+		if requests != 3 {
+			t.Errorf("expected 3 requests (create, get, delete) but got %d", requests)
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("includes ACS request ID in errors", func(t *testing.T) {
+		wantError := "received error response 500-internal: something broke (request ID req-1234)"
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "req-1234")
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"code":"500-internal","message":"something broke"}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		err := testClient.DeleteToken(t.Context(), "bar")
+		if err == nil || err.Error() != wantError {
+			t.Errorf("expected error %q but got %v", wantError, err)
+		}
+	})
+
+	t.Run("reads request ID from configured header", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Splunk-Request-Id", "req-5678")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code":"400-bad-request","message":"bad"}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithRequestIDHeader("X-Splunk-Request-Id")(testClient)
+		_, err := testClient.CreateToken(t.Context(), HECToken{Spec: v1alpha1.SplunkTokenSpec{Name: "bar"}})
+		if err == nil || !strings.Contains(err.Error(), "req-5678") {
+			t.Errorf("expected error to contain request ID req-5678 but got %v", err)
+		}
+	})
+}
+
+func TestErrorBodyLimit(t *testing.T) {
+	t.Run("bounds the read of an oversized error body", func(t *testing.T) {
+		body := &countingReader{Reader: strings.NewReader(`{"code":"500","message":"` + strings.Repeat("x", 1<<20) + `"}`)}
+		testClient := createTestClient("https://splunk.example.com")
+		testClient.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Status:     "500 Internal Server Error",
+				StatusCode: http.StatusInternalServerError,
+				Body:       io.NopCloser(body),
+			}, nil
+		})
+		WithMaxErrorBodySize(1024)(testClient)
+
+		err := testClient.DeleteToken(t.Context(), "bar")
+		if err == nil {
+			t.Fatal("expected error but did not receive one")
+		}
+		if body.read > 1024 {
+			t.Errorf("expected at most 1024 bytes to be read but read %d", body.read)
+		}
+		if !strings.Contains(err.Error(), "500 Internal Server Error") {
+			t.Errorf("expected truncated error to include the response status but got %s", err)
+		}
+	})
+}
+
+func TestRequestLatency(t *testing.T) {
+	t.Run("records the duration of each request", func(t *testing.T) {
+		const delay = 20 * time.Millisecond
+		testClient := createTestClient("https://splunk.example.com")
+		testClient.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			time.Sleep(delay)
+			return &http.Response{StatusCode: http.StatusAccepted, Body: http.NoBody}, nil
+		})
+		before := histogramValue(t, "delete")
+
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+
+		after := histogramValue(t, "delete")
+		if got := after.GetSampleCount() - before.GetSampleCount(); got != 1 {
+			t.Errorf("expected 1 delete request to be observed but got %d", got)
+		}
+		if got := after.GetSampleSum() - before.GetSampleSum(); got < delay.Seconds() {
+			t.Errorf("expected observed latency of at least %s but got %fs", delay, got)
+		}
+	})
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Run("fails a slow request", func(t *testing.T) {
+		release := make(chan struct{})
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer splunkServer.Close()
+		defer close(release)
+
+		testClient := createTestClient(splunkServer.URL)
+		WithRequestTimeout(20 * time.Millisecond)(testClient)
+
+		start := time.Now()
+		if err := testClient.DeleteToken(t.Context(), "bar"); err == nil {
+			t.Fatal("expected timeout error but did not receive one")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected request to time out quickly but it took %s", elapsed)
+		}
+	})
+}
+
+func histogramValue(t *testing.T, operation string) *dto.Histogram {
+	t.Helper()
+	var metric dto.Metric
+	if err := metrics.ACSRequestDuration.WithLabelValues(operation).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("error reading metric: %s", err)
+	}
+	return metric.GetHistogram()
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type countingReader struct {
+	io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.read += n
+	return n, err
 }
 
 // helper function to create a Client with the hostname set to the URL of the test server
 func createTestClient(testHostname string) *Client {
 	c, _ := NewClient("mock_splunk", "foo")
 	c.url = strings.Replace(c.url, acsHostname, testHostname, 1)
+	c.indexesURL = strings.Replace(c.indexesURL, acsHostname, testHostname, 1)
 	return c
 }
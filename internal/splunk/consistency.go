@@ -0,0 +1,102 @@
+package splunkapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultConsistencyInterval is the wait between checks for a token that returned 404 on delete.
+const defaultConsistencyInterval = time.Second
+
+// recentTokens remembers when the Client created tokens, since Splunk may return 404 when
+// deleting a token created moments ago even though the token exists.
+type recentTokens struct {
+	window   time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	created map[string]time.Time
+}
+
+// WithDeleteConsistencyWindow keeps checking a token created by the Client less than window ago
+// when deleting it returns 404, every interval (1 second when zero) until the window has passed.
+// If the token shows up it is deleted again, so it is not leaked. Disabled by default, so 404 on
+// delete always means the token is gone.
+func WithDeleteConsistencyWindow(window, interval time.Duration) ClientOption {
+	return func(c *Client) {
+		if window <= 0 {
+			return
+		}
+		if interval <= 0 {
+			interval = defaultConsistencyInterval
+		}
+		c.recent = &recentTokens{
+			window:   window,
+			interval: interval,
+			now:      time.Now,
+			created:  map[string]time.Time{},
+		}
+	}
+}
+
+// add records that the named token was created.
+func (t *recentTokens) add(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.created[name] = t.now()
+}
+
+// remove forgets the named token once it is deleted.
+func (t *recentTokens) remove(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.created, name)
+}
+
+// isRecent reports whether the named token was created within the window.
+func (t *recentTokens) isRecent(name string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	created, found := t.created[name]
+	return found && t.now().Sub(created) < t.window
+}
+
+// awaitToken waits for a recently created token that returned 404 on delete to show up, checking
+// every interval. It reports whether the token exists, and false once the window has passed
+// without finding it. Each check spends a retry of the context's retry budget.
+func (c *Client) awaitToken(ctx context.Context, name string) (bool, error) {
+	for c.recent.isRecent(name) {
+		if !SpendRetry(ctx) {
+			return false, ErrRetryBudgetExhausted
+		}
+		timer := time.NewTimer(c.recent.interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		}
+		_, err := c.GetToken(ctx, name)
+		if err == nil {
+			logf.FromContext(ctx).Info("HEC token still exists after delete returned not found, deleting again",
+				"token", name)
+			return true, nil
+		} else if !IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
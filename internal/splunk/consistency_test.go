@@ -0,0 +1,127 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+// consistencyServer serves a single token whose first deletes return 404 although it exists.
+type consistencyServer struct {
+	mu       sync.Mutex
+	exists   bool
+	notFound int
+	deletes  int
+	gets     int
+}
+
+func (s *consistencyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPost:
+		s.exists = true
+	case http.MethodGet:
+		s.gets += 1
+		if !s.exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"http-event-collector":{"spec":{"name":"bar"},"token":"baz"}}`)
+	case http.MethodDelete:
+		s.deletes += 1
+		if !s.exists || s.deletes <= s.notFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.exists = false
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func TestDeleteConsistencyWindow(t *testing.T) {
+	token := HECToken{Spec: v1alpha1.SplunkTokenSpec{Name: "bar"}}
+
+	t.Run("deletes again a new token found after 404", func(t *testing.T) {
+		server := &consistencyServer{notFound: 1}
+		splunkServer := httptest.NewServer(server)
+		defer splunkServer.Close()
+		testClient := createTestClient(splunkServer.URL)
+		WithDeleteConsistencyWindow(time.Minute, time.Millisecond)(testClient)
+
+		if _, err := testClient.CreateToken(t.Context(), token); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if server.deletes != 2 || server.exists {
+			t.Errorf("expected token to be deleted on the second attempt but got %d deletes, exists %t", server.deletes, server.exists)
+		}
+		if testClient.recent.isRecent("bar") {
+			t.Error("expected deleted token to be forgotten")
+		}
+	})
+
+	t.Run("trusts 404 for token not created recently", func(t *testing.T) {
+		server := &consistencyServer{exists: true, notFound: 1}
+		splunkServer := httptest.NewServer(server)
+		defer splunkServer.Close()
+		testClient := createTestClient(splunkServer.URL)
+		WithDeleteConsistencyWindow(time.Minute, time.Millisecond)(testClient)
+
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if server.deletes != 1 || server.gets != 0 {
+			t.Errorf("expected a single delete without checks but got %d deletes and %d gets", server.deletes, server.gets)
+		}
+	})
+
+	t.Run("stops checking once the window passes", func(t *testing.T) {
+		server := &consistencyServer{}
+		splunkServer := httptest.NewServer(server)
+		defer splunkServer.Close()
+		testClient := createTestClient(splunkServer.URL)
+		WithDeleteConsistencyWindow(time.Minute, time.Millisecond)(testClient)
+
+		now := time.Now()
+		testClient.recent.now = func() time.Time { return now }
+		testClient.recent.add("bar")
+		var checks int
+		testClient.recent.now = func() time.Time {
+			checks += 1
+			return now.Add(time.Duration(checks) * 20 * time.Second)
+		}
+
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if server.deletes != 1 || server.gets != 2 {
+			t.Errorf("expected one delete and checks until the window passed but got %d deletes and %d gets", server.deletes, server.gets)
+		}
+	})
+
+	t.Run("trusts 404 when disabled", func(t *testing.T) {
+		server := &consistencyServer{notFound: 1}
+		splunkServer := httptest.NewServer(server)
+		defer splunkServer.Close()
+		testClient := createTestClient(splunkServer.URL)
+
+		if _, err := testClient.CreateToken(t.Context(), token); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if server.deletes != 1 || !server.exists {
+			t.Errorf("expected a single delete leaving the token but got %d deletes, exists %t", server.deletes, server.exists)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package splunkapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+// enterpriseTokenPath is the management API path of HEC tokens on Splunk Enterprise.
+const enterpriseTokenPath string = "services/data/inputs/http" // #nosec G101 -- not a credential
+
+// enterpriseAPI manages tokens through the REST API on the management port of a
+// Splunk Enterprise instance. Requests are form encoded and responses are requested as JSON.
+type enterpriseAPI struct{}
+
+type enterpriseTokenResponse struct {
+	Entry []struct {
+		Name    string `json:"name"`
+		Content struct {
+			Token       string   `json:"token"`
+			Index       string   `json:"index"`
+			Indexes     []string `json:"indexes"`
+			Sourcetype  string   `json:"sourcetype"`
+			Description string   `json:"description"`
+			Disabled    bool     `json:"disabled"`
+		} `json:"content"`
+	} `json:"entry"`
+}
+
+// WithEnterpriseAPI manages tokens through the REST API of the Splunk Enterprise instance at
+// managementURL (e.g. https://splunk.example.com:8089) instead of ACS, which Splunk Enterprise
+// does not provide. Tokens are updated with POST, overriding an earlier WithUpdateMethod.
+// Token metadata and index validation are only supported through ACS.
+func WithEnterpriseAPI(managementURL string) ClientOption {
+	return func(c *Client) {
+		c.api = enterpriseAPI{}
+		c.url = strings.TrimSuffix(managementURL, "/") + "/" + enterpriseTokenPath
+		c.updateMethod = http.MethodPost
+	}
+}
+
+func (enterpriseAPI) tokenURL(base, name string) (string, error) {
+	tokenURL := base
+	if name != "" {
+		var err error
+		if tokenURL, err = url.JoinPath(base, name); err != nil {
+			return "", err
+		}
+	}
+	return tokenURL + "?output_mode=json", nil
+}
+
+// encodeToken sends the default index and the allowed indexes as a comma separated list.
+// The token name is part of the URL when updating, so it is only sent when creating.
+// Whether the token is disabled is always sent when updating so it can be enabled again.
+func (enterpriseAPI) encodeToken(spec v1alpha1.SplunkTokenSpec, _, _ map[string]string, _ FieldNames, create bool) ([]byte, string, error) {
+	form := url.Values{}
+	if create {
+		form.Set("name", spec.Name)
+	}
+	if spec.DefaultIndex != "" {
+		form.Set("index", spec.DefaultIndex)
+	}
+	if len(spec.AllowedIndexes) > 0 {
+		form.Set("indexes", strings.Join(spec.AllowedIndexes, ","))
+	}
+	if spec.Sourcetype != "" {
+		form.Set("sourcetype", spec.Sourcetype)
+	}
+	if spec.Description != "" {
+		form.Set("description", spec.Description)
+	}
+	if spec.Disabled || !create {
+		form.Set("disabled", strconv.FormatBool(spec.Disabled))
+	}
+	return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+}
+
+// decodeToken reads the token from the first entry of the response.
+// Splunk Enterprise names HEC inputs http://<token name>. Its responses carry many
+// fields the Client does not model, so they are never decoded strictly.
+func (enterpriseAPI) decodeToken(body io.Reader, _ bool) (*HECToken, error) {
+	tokens, err := decodeEnterpriseTokens(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, &errorResponse{Message: "no token in response", statusCode: http.StatusNotFound}
+	}
+	return &tokens[0], nil
+}
+
+func (enterpriseAPI) decodeTokenList(body io.Reader) ([]HECToken, error) {
+	return decodeEnterpriseTokens(body)
+}
+
+// decodeEnterpriseTokens reads the tokens from the entries of the response.
+func decodeEnterpriseTokens(body io.Reader) ([]HECToken, error) {
+	var response enterpriseTokenResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, err
+	}
+	tokens := make([]HECToken, 0, len(response.Entry))
+	for _, entry := range response.Entry {
+		tokens = append(tokens, HECToken{
+			Spec: v1alpha1.SplunkTokenSpec{
+				Name:           strings.TrimPrefix(entry.Name, "http://"),
+				DefaultIndex:   entry.Content.Index,
+				AllowedIndexes: entry.Content.Indexes,
+				Sourcetype:     entry.Content.Sourcetype,
+				Description:    entry.Content.Description,
+				Disabled:       entry.Content.Disabled,
+			},
+			Value: entry.Content.Token,
+		})
+	}
+	return tokens, nil
+}
+
+func (enterpriseAPI) deletedStatus() int {
+	return http.StatusOK
+}
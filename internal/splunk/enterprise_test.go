@@ -0,0 +1,148 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+func TestEnterpriseAPI(t *testing.T) {
+	const (
+		tokensPath   = "/services/data/inputs/http"
+		tokenPath    = "/services/data/inputs/http/bar"
+		tokenJSON    = `{"entry":[{"name":"http://bar","content":{"token":"baz","index":"main","indexes":["main","audit"]}}]}`
+		wantAuth     = "Bearer foo"
+		wantContent  = "application/x-www-form-urlencoded"
+		wantFormBody = "description=managed+by+splunk-token-operator&index=main&indexes=audit%2Cmain&name=bar&sourcetype=openshift"
+	)
+
+	t.Run("create request is formatted properly", func(t *testing.T) {
+		var serverCalls uint
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverCalls += 1
+			if r.URL.Query().Get("output_mode") != "json" {
+				t.Errorf("expected output_mode=json but got query %s", r.URL.RawQuery)
+			}
+			switch r.Method {
+			case http.MethodPost:
+				if r.URL.Path != tokensPath {
+					t.Errorf("expected POST request to %s but got %s", tokensPath, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != wantAuth {
+					t.Errorf("expected header Authorization with value '%s' but got '%s'", wantAuth, got)
+				}
+				if got := r.Header.Get("Content-Type"); got != wantContent {
+					t.Errorf("expected header Content-Type with value '%s' but got '%s'", wantContent, got)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != wantFormBody {
+					t.Errorf("expected request body %s but got %s", wantFormBody, body)
+				}
+				w.WriteHeader(http.StatusCreated)
+			case http.MethodGet:
+				if r.URL.Path != tokenPath {
+					t.Errorf("expected GET request to %s but got %s", tokenPath, r.URL.Path)
+				}
+				io.WriteString(w, tokenJSON)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient, _ := NewClient("mock_splunk", "foo", WithEnterpriseAPI(splunkServer.URL))
+		token, err := testClient.CreateToken(t.Context(), HECToken{
+			Spec: v1alpha1.SplunkTokenSpec{
+				Name:           "bar",
+				DefaultIndex:   "main",
+				AllowedIndexes: []string{"audit"},
+				Sourcetype:     "openshift",
+				Description:    "managed by splunk-token-operator",
+			},
+		})
+		if err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if serverCalls != 2 {
+			t.Errorf("expected 2 requests to test server but got %d", serverCalls)
+		}
+		if token.Value != "baz" {
+			t.Errorf("expected token value baz but got %s", token.Value)
+		}
+		if token.Spec.Name != "bar" {
+			t.Errorf("expected token name bar but got %s", token.Spec.Name)
+		}
+		if !slices.Equal(token.Spec.AllowedIndexes, []string{"main", "audit"}) {
+			t.Errorf("expected allowed indexes [main audit] but got %v", token.Spec.AllowedIndexes)
+		}
+	})
+
+	t.Run("update request does not send the token name", func(t *testing.T) {
+		var gotMethod string
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				io.WriteString(w, tokenJSON)
+				return
+			}
+			gotMethod = r.Method
+			if r.URL.Path != tokenPath {
+				t.Errorf("expected request to %s but got %s", tokenPath, r.URL.Path)
+			}
+			body, _ := io.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			if form.Has("name") {
+				t.Errorf("expected update request without name but got %s", body)
+			}
+			if form.Get("disabled") != "false" {
+				t.Errorf("expected update request to enable the token but got %s", body)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient, _ := NewClient("mock_splunk", "foo", WithUpdateMethod(http.MethodPatch), WithEnterpriseAPI(splunkServer.URL))
+		if _, err := testClient.UpdateToken(t.Context(), HECToken{
+			Spec: v1alpha1.SplunkTokenSpec{Name: "bar", DefaultIndex: "main"},
+		}); err != nil {
+			t.Fatalf("got unexpected error: %s", err)
+		}
+		if gotMethod != http.MethodPost {
+			t.Errorf("expected POST request but got %s", gotMethod)
+		}
+	})
+
+	t.Run("delete succeeds with status OK", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodDelete || r.URL.Path != tokenPath {
+				t.Errorf("expected DELETE request to %s but got %s %s", tokenPath, r.Method, r.URL.Path)
+			}
+		}))
+		defer splunkServer.Close()
+
+		testClient, _ := NewClient("mock_splunk", "foo", WithEnterpriseAPI(splunkServer.URL+"/"))
+		if err := testClient.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Errorf("got unexpected error: %s", err)
+		}
+	})
+
+	t.Run("reads error messages", func(t *testing.T) {
+		wantError := "received error response : Could not find object id=bar"
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"messages":[{"type":"ERROR","text":"Could not find object id=bar"}]}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient, _ := NewClient("mock_splunk", "foo", WithEnterpriseAPI(splunkServer.URL))
+		_, err := testClient.GetToken(t.Context(), "bar")
+		if !IsNotFound(err) {
+			t.Errorf("expected not found error but got %v", err)
+		}
+		if err != nil && err.Error() != wantError {
+			t.Errorf("expected error %s but got %s", wantError, err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+// Package faketokens provides a TokenManager that stores HEC tokens locally
+// instead of in Splunk, for running the operator end-to-end without a Splunk instance.
+package faketokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+// A Manager implements splunkapi.TokenManager using an in-memory store,
+// optionally persisted to a JSON file so tokens survive operator restarts.
+type Manager struct {
+	path string
+
+	mu     sync.Mutex
+	tokens map[string]splunkapi.HECToken
+}
+
+var (
+	_ splunkapi.TokenManager = &Manager{}
+	_ splunkapi.TokenLister  = &Manager{}
+)
+
+// New creates a Manager that persists tokens to the file at path, loading any tokens
+// already stored there. If path is empty tokens are only kept in memory.
+func New(path string) (*Manager, error) {
+	m := &Manager{
+		path:   path,
+		tokens: map[string]splunkapi.HECToken{},
+	}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.tokens); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// CreateToken stores a new token with a random GUID value, the format Splunk uses. Like Splunk, creating a token
+// that already exists returns the existing token.
+func (m *Manager) CreateToken(ctx context.Context, token splunkapi.HECToken) (*splunkapi.HECToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, found := m.tokens[token.Spec.Name]; found {
+		return &existing, nil
+	}
+	token.Value = uuid.NewString()
+	token.Metadata = nil
+	m.tokens[token.Spec.Name] = token
+	if err := m.save(); err != nil {
+		delete(m.tokens, token.Spec.Name)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UpdateToken replaces the spec of an existing token, keeping its value.
+// If the token does not exist the error satisfies splunkapi.IsNotFound.
+func (m *Manager) UpdateToken(ctx context.Context, token splunkapi.HECToken) (*splunkapi.HECToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, found := m.tokens[token.Spec.Name]
+	if !found {
+		return nil, fmt.Errorf("token %s: %w", token.Spec.Name, splunkapi.ErrNotFound)
+	}
+	updated := existing
+	updated.Spec = token.Spec
+	m.tokens[token.Spec.Name] = updated
+	if err := m.save(); err != nil {
+		m.tokens[token.Spec.Name] = existing
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteToken removes the named token. Deleting a token that does not exist is not an error.
+func (m *Manager) DeleteToken(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, found := m.tokens[name]
+	if !found {
+		return nil
+	}
+	delete(m.tokens, name)
+	if err := m.save(); err != nil {
+		m.tokens[name] = token
+		return err
+	}
+	return nil
+}
+
+// GetToken returns the named token, or an error for which splunkapi.IsNotFound is true.
+func (m *Manager) GetToken(ctx context.Context, name string) (*splunkapi.HECToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, found := m.tokens[name]
+	if !found {
+		return nil, fmt.Errorf("token %s: %w", name, splunkapi.ErrNotFound)
+	}
+	return &token, nil
+}
+
+// ListTokens returns every stored token, in no particular order.
+func (m *Manager) ListTokens(ctx context.Context) ([]splunkapi.HECToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := make([]splunkapi.HECToken, 0, len(m.tokens))
+	for _, token := range m.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// save writes the tokens to the Manager's file, if it has one.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(m.tokens)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0o600)
+}
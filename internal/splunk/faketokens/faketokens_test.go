@@ -0,0 +1,122 @@
+package faketokens
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+	splunkapi "github.com/openshift/splunk-token-operator/internal/splunk"
+)
+
+var guidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+func TestManager(t *testing.T) {
+	token := splunkapi.HECToken{
+		Spec: v1alpha1.SplunkTokenSpec{
+			Name:         "bar",
+			DefaultIndex: "main",
+		},
+	}
+
+	t.Run("create and delete round trip in memory", func(t *testing.T) {
+		m, err := New("")
+		if err != nil {
+			t.Fatalf("error creating manager: %s", err)
+		}
+
+		created, err := m.CreateToken(t.Context(), token)
+		if err != nil {
+			t.Fatalf("error creating token: %s", err)
+		}
+		if !guidPattern.MatchString(created.Value) {
+			t.Errorf("expected GUID token value but got %s", created.Value)
+		}
+		again, err := m.CreateToken(t.Context(), token)
+		if err != nil {
+			t.Fatalf("error creating existing token: %s", err)
+		}
+		if again.Value != created.Value {
+			t.Errorf("expected existing token value %s but got %s", created.Value, again.Value)
+		}
+		got, err := m.GetToken(t.Context(), "bar")
+		if err != nil {
+			t.Fatalf("error getting token: %s", err)
+		}
+		if got.Spec.DefaultIndex != "main" {
+			t.Errorf("expected default index main but got %s", got.Spec.DefaultIndex)
+		}
+
+		if err := m.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("error deleting token: %s", err)
+		}
+		if _, err := m.GetToken(t.Context(), "bar"); !splunkapi.IsNotFound(err) {
+			t.Errorf("expected not found error after delete but got %v", err)
+		}
+		if err := m.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Errorf("expected deleting missing token to succeed but got %s", err)
+		}
+	})
+
+	t.Run("updates token spec and keeps its value", func(t *testing.T) {
+		m, err := New("")
+		if err != nil {
+			t.Fatalf("error creating manager: %s", err)
+		}
+		if _, err := m.UpdateToken(t.Context(), token); !splunkapi.IsNotFound(err) {
+			t.Errorf("expected not found error updating missing token but got %v", err)
+		}
+		created, err := m.CreateToken(t.Context(), token)
+		if err != nil {
+			t.Fatalf("error creating token: %s", err)
+		}
+
+		changed := token
+		changed.Spec.DefaultIndex = "audit"
+		updated, err := m.UpdateToken(t.Context(), changed)
+		if err != nil {
+			t.Fatalf("error updating token: %s", err)
+		}
+		if updated.Spec.DefaultIndex != "audit" {
+			t.Errorf("expected default index audit but got %s", updated.Spec.DefaultIndex)
+		}
+		if updated.Value != created.Value {
+			t.Errorf("expected token value %s to be kept but got %s", created.Value, updated.Value)
+		}
+	})
+
+	t.Run("persists tokens to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tokens.json")
+		m, err := New(path)
+		if err != nil {
+			t.Fatalf("error creating manager: %s", err)
+		}
+		created, err := m.CreateToken(t.Context(), token)
+		if err != nil {
+			t.Fatalf("error creating token: %s", err)
+		}
+
+		reloaded, err := New(path)
+		if err != nil {
+			t.Fatalf("error reloading manager: %s", err)
+		}
+		got, err := reloaded.GetToken(t.Context(), "bar")
+		if err != nil {
+			t.Fatalf("error getting reloaded token: %s", err)
+		}
+		if got.Value != created.Value {
+			t.Errorf("expected reloaded token value %s but got %s", created.Value, got.Value)
+		}
+
+		if err := reloaded.DeleteToken(t.Context(), "bar"); err != nil {
+			t.Fatalf("error deleting token: %s", err)
+		}
+		reloaded, err = New(path)
+		if err != nil {
+			t.Fatalf("error reloading manager: %s", err)
+		}
+		if _, err := reloaded.GetToken(t.Context(), "bar"); !splunkapi.IsNotFound(err) {
+			t.Errorf("expected deleted token to stay deleted but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package splunkapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	indexesPath string = "adminconfig/v2/indexes"
+
+	// indexPageSize is the largest page of indexes ACS returns per request.
+	indexPageSize int = 100
+)
+
+// An indexCache holds the names of the indexes on the Splunk instance
+// so they are not listed from ACS for every token created.
+type indexCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	names     map[string]bool
+	fetchedAt time.Time
+}
+
+type indexResponse struct {
+	Name string `json:"name"`
+}
+
+// WithIndexValidation makes CreateToken fail if the token's indexes do not exist
+// on the Splunk instance. The list of indexes is cached for ttl.
+func WithIndexValidation(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.indexes = &indexCache{ttl: ttl, now: time.Now}
+	}
+}
+
+// InvalidateIndexCache discards the cached list of indexes so the next validation lists them again.
+func (c *Client) InvalidateIndexCache() {
+	if c.indexes == nil {
+		return
+	}
+	c.indexes.mu.Lock()
+	defer c.indexes.mu.Unlock()
+	c.indexes.names = nil
+}
+
+// validateIndexes returns an error naming any of the indexes that do not exist on the Splunk instance.
+func (c *Client) validateIndexes(ctx context.Context, indexes []string) error {
+	c.indexes.mu.Lock()
+	defer c.indexes.mu.Unlock()
+
+	if c.indexes.names == nil || c.indexes.now().After(c.indexes.fetchedAt.Add(c.indexes.ttl)) {
+		names, err := c.listIndexes(ctx)
+		if err != nil {
+			return err
+		}
+		c.indexes.names = names
+		c.indexes.fetchedAt = c.indexes.now()
+	}
+	var missing []string
+	for _, index := range indexes {
+		if !c.indexes.names[index] && !slices.Contains(missing, index) {
+			missing = append(missing, index)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("indexes do not exist on the Splunk instance: %v", missing)
+	}
+	return nil
+}
+
+// listIndexes returns the names of all indexes on the Splunk instance, reading every page.
+func (c *Client) listIndexes(ctx context.Context) (map[string]bool, error) {
+	names := map[string]bool{}
+	for offset := 0; ; offset += indexPageSize {
+		query := url.Values{
+			"count":  {strconv.Itoa(indexPageSize)},
+			"offset": {strconv.Itoa(offset)},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.indexesURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
+
+		res, err := c.do(req, "list_indexes", "")
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode >= 400 {
+			err := c.decodeError(res)
+			res.Body.Close()
+			return nil, err
+		}
+		var page []indexResponse
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range page {
+			names[index.Name] = true
+		}
+		if len(page) < indexPageSize {
+			return names, nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+func TestIndexValidation(t *testing.T) {
+	newServer := func(listCalls *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/indexes"):
+				*listCalls += 1
+				io.WriteString(w, `[{"name":"main"},{"name":"audit"}]`)
+			case r.Method == http.MethodGet:
+				io.WriteString(w, `{"http-event-collector":{"spec":{"name":"bar"}}}`)
+			}
+		}))
+	}
+	token := func(indexes ...string) HECToken {
+		return HECToken{Spec: v1alpha1.SplunkTokenSpec{Name: "bar", AllowedIndexes: indexes}}
+	}
+
+	t.Run("rejects indexes that do not exist", func(t *testing.T) {
+		var listCalls int
+		splunkServer := newServer(&listCalls)
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithIndexValidation(time.Minute)(testClient)
+
+		if _, err := testClient.CreateToken(t.Context(), token("main")); err != nil {
+			t.Errorf("got unexpected error %s", err)
+		}
+		_, err := testClient.CreateToken(t.Context(), token("main", "missing"))
+		if err == nil || !strings.Contains(err.Error(), "missing") {
+			t.Errorf("expected error naming missing index but got %v", err)
+		}
+	})
+
+	t.Run("reuses cached indexes within TTL", func(t *testing.T) {
+		var listCalls int
+		splunkServer := newServer(&listCalls)
+		defer splunkServer.Close()
+
+		now := time.Now()
+		testClient := createTestClient(splunkServer.URL)
+		WithIndexValidation(time.Minute)(testClient)
+		testClient.indexes.now = func() time.Time { return now }
+
+		testClient.CreateToken(t.Context(), token("main"))
+		testClient.CreateToken(t.Context(), token("audit"))
+		if listCalls != 1 {
+			t.Errorf("expected 1 index list call within TTL but got %d", listCalls)
+		}
+
+		now = now.Add(time.Minute + time.Second)
+		testClient.CreateToken(t.Context(), token("main"))
+		if listCalls != 2 {
+			t.Errorf("expected indexes to be listed again after TTL but got %d calls", listCalls)
+		}
+
+		testClient.InvalidateIndexCache()
+		testClient.CreateToken(t.Context(), token("main"))
+		if listCalls != 3 {
+			t.Errorf("expected indexes to be listed again after invalidation but got %d calls", listCalls)
+		}
+	})
+
+	t.Run("reads every page of indexes", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("offset") != "0" {
+				io.WriteString(w, `[{"name":"last"}]`)
+				return
+			}
+			var page []string
+			for i := range indexPageSize {
+				page = append(page, fmt.Sprintf(`{"name":"index-%d"}`, i))
+			}
+			io.WriteString(w, "["+strings.Join(page, ",")+"]")
+		}))
+		defer splunkServer.Close()
+
+		testClient := createTestClient(splunkServer.URL)
+		WithIndexValidation(time.Minute)(testClient)
+		if err := testClient.validateIndexes(t.Context(), []string{"index-0", "last"}); err != nil {
+			t.Errorf("got unexpected error %s", err)
+		}
+	})
+}
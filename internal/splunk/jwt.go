@@ -0,0 +1,43 @@
+package splunkapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrMalformedJWT is returned by NewClient when JWT validation is enabled and the
+// authentication token is not a well-formed JWT.
+var ErrMalformedJWT = errors.New("splunk authentication token is not a well-formed JWT")
+
+// WithJWTValidation makes NewClient check that the authentication token is a well-formed JWT,
+// so a malformed token fails at startup rather than on the first request. It is not enabled
+// by default because some deployments authenticate with opaque tokens.
+func WithJWTValidation(validate bool) ClientOption {
+	return func(c *Client) {
+		c.validateJWT = validate
+	}
+}
+
+// checkJWT reports whether jwt has three base64url encoded segments with a JSON object header.
+// The signature is not verified, since only Splunk can do that.
+func checkJWT(jwt string) error {
+	segments := strings.Split(jwt, ".")
+	if len(segments) != 3 {
+		return ErrMalformedJWT
+	}
+	decoded := make([][]byte, len(segments))
+	for i, segment := range segments {
+		var err error
+		decoded[i], err = base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+		if err != nil || len(decoded[i]) == 0 {
+			return ErrMalformedJWT
+		}
+	}
+	var header map[string]any
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return ErrMalformedJWT
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package splunkapi
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLimits throttles the requests a Client sends to its Splunk instance.
+// Each Client manages a single instance, so the limits of one instance never
+// delay requests to another.
+type requestLimits struct {
+	limiter  *rate.Limiter
+	inflight chan struct{}
+}
+
+// WithRateLimit limits requests to the Splunk instance to qps per second, allowing bursts of
+// up to burst requests. A request waits for the limiter, or fails if its context is done first.
+// A qps of zero disables the limit.
+func WithRateLimit(qps float64, burst int) ClientOption {
+	return func(c *Client) {
+		if qps > 0 {
+			c.limits.limiter = rate.NewLimiter(rate.Limit(qps), max(burst, 1))
+		}
+	}
+}
+
+// WithConcurrencyLimit caps the number of requests in flight to the Splunk instance.
+// A limit of zero disables the cap.
+func WithConcurrencyLimit(limit int) ClientOption {
+	return func(c *Client) {
+		if limit > 0 {
+			c.limits.inflight = make(chan struct{}, limit)
+		}
+	}
+}
+
+// acquire waits until a request may be sent and returns a function releasing its slot.
+func (l *requestLimits) acquire(ctx context.Context) (release func(), err error) {
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if l.inflight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inflight <- struct{}{}:
+		return func() { <-l.inflight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
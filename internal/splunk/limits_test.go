@@ -0,0 +1,97 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	var inflight, maxInflight atomic.Int32
+	release := make(chan struct{})
+	blockedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			current := maxInflight.Load()
+			if n <= current || maxInflight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer blockedServer.Close()
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer otherServer.Close()
+
+	blockedClient := createTestClient(blockedServer.URL)
+	WithConcurrencyLimit(1)(blockedClient)
+	otherClient := createTestClient(otherServer.URL)
+	WithConcurrencyLimit(1)(otherClient)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			blockedClient.DeleteToken(t.Context(), "bar")
+		}()
+	}
+
+	// requests to another instance proceed while the first instance is at its limit
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	if err := otherClient.DeleteToken(ctx, "bar"); err != nil {
+		t.Errorf("expected request to other instance to succeed but got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := maxInflight.Load(); n != 1 {
+		t.Errorf("expected at most 1 request in flight but got %d", n)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer splunkServer.Close()
+
+	limitedClient := createTestClient(splunkServer.URL)
+	WithRateLimit(0.01, 1)(limitedClient)
+	otherClient := createTestClient(splunkServer.URL)
+	WithRateLimit(0.01, 1)(otherClient)
+
+	if err := limitedClient.DeleteToken(t.Context(), "bar"); err != nil {
+		t.Fatalf("expected first request to succeed but got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	if err := limitedClient.DeleteToken(ctx, "bar"); err == nil {
+		t.Error("expected throttled request to fail before its deadline")
+	}
+	if err := otherClient.DeleteToken(t.Context(), "bar"); err != nil {
+		t.Errorf("expected request from another client to be unaffected but got %v", err)
+	}
+}
+
+func TestRequestLimitsCanceled(t *testing.T) {
+	limits := requestLimits{inflight: make(chan struct{}, 1)}
+	limits.inflight <- struct{}{}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := limits.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled error while waiting for a slot but got %v", err)
+	}
+}
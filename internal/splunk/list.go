@@ -0,0 +1,82 @@
+package splunkapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// tokenPageSize is the number of tokens requested per page when listing tokens.
+const tokenPageSize int = 100
+
+// A TokenLister lists every HEC token on a Splunk instance.
+type TokenLister interface {
+	ListTokens(context.Context) ([]HECToken, error)
+}
+
+var _ TokenLister = &Client{}
+
+type tokenListResponse struct {
+	Data []acsToken `json:"http-event-collectors"`
+}
+
+// ListTokens returns every HEC token on the Splunk instance, reading every page.
+func (c *Client) ListTokens(ctx context.Context) ([]HECToken, error) {
+	base, err := c.api.tokenURL(c.url, "")
+	if err != nil {
+		return nil, err
+	}
+	listURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []HECToken
+	for offset := 0; ; offset += tokenPageSize {
+		query := listURL.Query()
+		query.Set("count", strconv.Itoa(tokenPageSize))
+		query.Set("offset", strconv.Itoa(offset))
+		listURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.jwt))
+
+		res, err := c.do(req, "list", "")
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode >= 400 {
+			err := c.decodeError(res)
+			res.Body.Close()
+			return nil, err
+		}
+		page, err := c.api.decodeTokenList(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page...)
+		if len(page) < tokenPageSize {
+			return tokens, nil
+		}
+	}
+}
+
+func (acsAPI) decodeTokenList(body io.Reader) ([]HECToken, error) {
+	var response tokenListResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, err
+	}
+	tokens := make([]HECToken, 0, len(response.Data))
+	for _, token := range response.Data {
+		tokens = append(tokens, *token.hecToken())
+	}
+	return tokens, nil
+}
@@ -0,0 +1,79 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestListTokens(t *testing.T) {
+	t.Run("reads every page of ACS tokens", func(t *testing.T) {
+		const total = tokenPageSize + 5
+		var offsets []string
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			var entries []string
+			for i := offset; i < min(offset+tokenPageSize, total); i++ {
+				entries = append(entries, fmt.Sprintf(`{"spec":{"name":"token-%d"},"token":"value-%d"}`, i, i))
+			}
+			fmt.Fprintf(w, `{"http-event-collectors":[%s]}`, strings.Join(entries, ","))
+		}))
+		defer splunkServer.Close()
+
+		tokens, err := createTestClient(splunkServer.URL).ListTokens(t.Context())
+		if err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if len(tokens) != total {
+			t.Errorf("expected %d tokens but got %d", total, len(tokens))
+		}
+		if last := tokens[len(tokens)-1]; last.Spec.Name != "token-104" || last.Value != "value-104" {
+			t.Errorf("expected last token token-104 but got %v", last)
+		}
+		if want := []string{"0", "100"}; strings.Join(offsets, ",") != strings.Join(want, ",") {
+			t.Errorf("expected requests at offsets %v but got %v", want, offsets)
+		}
+	})
+
+	t.Run("lists Splunk Enterprise tokens", func(t *testing.T) {
+		var query string
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			io.WriteString(w, `{"entry":[{"name":"http://foo","content":{"token":"one"}},{"name":"http://bar","content":{"token":"two"}}]}`)
+		}))
+		defer splunkServer.Close()
+
+		testClient, err := NewClient("mock_splunk", "foo", WithEnterpriseAPI(splunkServer.URL))
+		if err != nil {
+			t.Fatalf("error creating client: %s", err)
+		}
+		tokens, err := testClient.ListTokens(t.Context())
+		if err != nil {
+			t.Fatalf("got unexpected error %s", err)
+		}
+		if len(tokens) != 2 || tokens[0].Spec.Name != "foo" || tokens[1].Value != "two" {
+			t.Errorf("expected tokens foo and bar but got %v", tokens)
+		}
+		if !strings.Contains(query, "output_mode=json") {
+			t.Errorf("expected JSON output mode to be kept in query %q", query)
+		}
+	})
+
+	t.Run("returns error response", func(t *testing.T) {
+		splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"code":"403-forbidden","message":"forbidden"}`)
+		}))
+		defer splunkServer.Close()
+
+		if _, err := createTestClient(splunkServer.URL).ListTokens(t.Context()); !IsForbidden(err) {
+			t.Errorf("expected forbidden error but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package splunkapi
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrMaintenance matches error responses whose code is one of the Client's maintenance error codes.
+var ErrMaintenance = errors.New("maintenance in progress on Splunk")
+
+// WithMaintenanceErrorCodes treats error responses with one of codes as a sign that the Splunk
+// instance is under maintenance, so IsMaintenance reports true for them. None by default.
+func WithMaintenanceErrorCodes(codes []string) ClientOption {
+	return func(c *Client) {
+		c.maintenanceCodes = codes
+	}
+}
+
+// IsMaintenance reports whether err is an error response indicating the Splunk instance is under
+// maintenance, as configured with WithMaintenanceErrorCodes.
+func IsMaintenance(err error) bool {
+	return errors.Is(err, ErrMaintenance)
+}
+
+// isMaintenanceCode reports whether the error code is one of the Client's maintenance error codes.
+func (c *Client) isMaintenanceCode(code string) bool {
+	return code != "" && slices.Contains(c.maintenanceCodes, code)
+}
@@ -0,0 +1,41 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaintenanceErrorCodes(t *testing.T) {
+	splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, `{"code":"503-maintenance","message":"stack is under maintenance"}`)
+	}))
+	defer splunkServer.Close()
+
+	tests := []struct {
+		name  string
+		codes []string
+		want  bool
+	}{
+		{name: "reports maintenance error code", codes: []string{"503-maintenance"}, want: true},
+		{name: "ignores other error codes", codes: []string{"503-upgrade"}},
+		{name: "ignores error codes by default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testClient := createTestClient(splunkServer.URL)
+			WithMaintenanceErrorCodes(tt.codes)(testClient)
+
+			_, err := testClient.GetToken(t.Context(), "bar")
+			if err == nil {
+				t.Fatal("expected error response")
+			}
+			if got := IsMaintenance(err); got != tt.want {
+				t.Errorf("expected IsMaintenance %t but got %t for %v", tt.want, got, err)
+			}
+		})
+	}
+}
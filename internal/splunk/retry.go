@@ -0,0 +1,72 @@
+package splunkapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// defaultRetryBackoff is the wait before the first retry of a request, doubled for each further retry.
+const defaultRetryBackoff = time.Second
+
+// codeRetry retries requests whose error responses carry one of the codes, regardless of their
+// HTTP status, since Splunk reports some transient conditions with a 400-class status.
+type codeRetry struct {
+	codes   []string
+	retries int
+	backoff time.Duration
+}
+
+// WithRetryableErrorCodes retries requests up to retries times when Splunk responds with an
+// error whose code is one of codes. No error codes are retried by default.
+func WithRetryableErrorCodes(codes []string, retries int) ClientOption {
+	return func(c *Client) {
+		if len(codes) > 0 && retries > 0 {
+			c.retry = &codeRetry{codes: codes, retries: retries, backoff: defaultRetryBackoff}
+		}
+	}
+}
+
+// shouldRetry reports whether the request may be retried after its attempt'th retry received res.
+// The body of an error response is buffered so it can still be decoded when it is not retried.
+func (c *Client) shouldRetry(req *http.Request, res *http.Response, attempt int) bool {
+	if c.retry == nil || attempt >= c.retry.retries || res.StatusCode < 400 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, c.maxErrorBodySize))
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	var response errorResponse
+	if json.Unmarshal(body, &response) != nil {
+		return false
+	}
+	return slices.Contains(c.retry.codes, response.Code)
+}
+
+// waitToRetry waits for the backoff of the attempt'th retry and resets the request body.
+// It returns an error if the request's context is done first.
+func (c *Client) waitToRetry(req *http.Request, attempt int) error {
+	timer := time.NewTimer(c.retry.backoff << attempt)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
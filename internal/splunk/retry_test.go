@@ -0,0 +1,101 @@
+//nolint:errcheck
+package splunkapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+func TestRetryableErrorCodes(t *testing.T) {
+	const transientError = `{"code":"transient-error","message":"try again later"}`
+
+	tests := []struct {
+		name      string
+		codes     []string
+		failures  int
+		response  string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "retries request with retryable error code",
+			codes:     []string{"transient-error"},
+			failures:  2,
+			response:  transientError,
+			wantCalls: 3,
+		},
+		{
+			name:      "returns error after retries are exhausted",
+			codes:     []string{"transient-error"},
+			failures:  5,
+			response:  transientError,
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry other error codes",
+			codes:     []string{"transient-error"},
+			failures:  1,
+			response:  `{"code":"invalid-request","message":"bad index"}`,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry error codes by default",
+			failures:  1,
+			response:  transientError,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createCalls int
+			splunkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					io.WriteString(w, `{"http-event-collector":{"spec":{"name":"bar"},"token":"value"}}`)
+					return
+				}
+				createCalls += 1
+				body, _ := io.ReadAll(r.Body)
+				if wantBody := `{"name":"bar"}`; string(body) != wantBody {
+					t.Errorf("expected request payload '%s' but got '%s'", wantBody, body)
+				}
+				if createCalls <= tt.failures {
+					w.WriteHeader(http.StatusBadRequest)
+					io.WriteString(w, tt.response)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer splunkServer.Close()
+
+			testClient := createTestClient(splunkServer.URL)
+			WithRetryableErrorCodes(tt.codes, 2)(testClient)
+			if testClient.retry != nil {
+				testClient.retry.backoff = 0
+			}
+
+			_, err := testClient.CreateToken(t.Context(), HECToken{Spec: v1alpha1.SplunkTokenSpec{Name: "bar"}})
+			if tt.wantErr {
+				var response *errorResponse
+				if !errors.As(err, &response) {
+					t.Fatalf("expected error response but got %v", err)
+				}
+				if response.statusCode != http.StatusBadRequest {
+					t.Errorf("expected error status %d but got %d", http.StatusBadRequest, response.statusCode)
+				}
+			} else if err != nil {
+				t.Errorf("expected request to succeed after retries but got %v", err)
+			}
+			if createCalls != tt.wantCalls {
+				t.Errorf("expected %d create requests but got %d", tt.wantCalls, createCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package splunkapi
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+// TokenMatchesSpec reports whether the live HEC token has the indexes, sourcetype, description
+// and enablement of the wanted spec. Splunk adds the default index to the allowed indexes, so they are compared
+// as sets including it. A sourcetype or description left empty in the spec is not managed and
+// matches any live value.
+func TokenMatchesSpec(live *HECToken, spec v1alpha1.SplunkTokenSpec) bool {
+	if !IndexesMatch(spec, live.Spec) || spec.Disabled != live.Spec.Disabled {
+		return false
+	}
+	if spec.Sourcetype != "" && spec.Sourcetype != live.Spec.Sourcetype {
+		return false
+	}
+	return spec.Description == "" || spec.Description == live.Spec.Description
+}
+
+// TokenTagsMatch reports whether the live HEC token has exactly the wanted tags.
+// Tags are not managed when none are wanted, and then match any live tags.
+func TokenTagsMatch(live *HECToken, tags map[string]string) bool {
+	return len(tags) == 0 || maps.Equal(live.Tags, tags)
+}
+
+// IndexesMatch reports whether two specs have the same default index and the same set of
+// allowed indexes, regardless of their order.
+func IndexesMatch(want, live v1alpha1.SplunkTokenSpec) bool {
+	if want.DefaultIndex != live.DefaultIndex {
+		return false
+	}
+	return slices.Equal(indexSet(want), indexSet(live))
+}
+
+// indexSet returns the sorted, deduplicated indexes a spec allows, including its default index.
+func indexSet(spec v1alpha1.SplunkTokenSpec) []string {
+	indexes := slices.Clone(spec.AllowedIndexes)
+	if spec.DefaultIndex != "" {
+		indexes = append(indexes, spec.DefaultIndex)
+	}
+	slices.Sort(indexes)
+	return slices.Compact(indexes)
+}
@@ -0,0 +1,126 @@
+package splunkapi
+
+import (
+	"testing"
+
+	"github.com/openshift/splunk-token-operator/api/v1alpha1"
+)
+
+func TestTokenMatchesSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  v1alpha1.SplunkTokenSpec
+		live  v1alpha1.SplunkTokenSpec
+		match bool
+	}{
+		{
+			name:  "matches identical spec",
+			spec:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"main", "audit"}, Sourcetype: "json"},
+			live:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"main", "audit"}, Sourcetype: "json"},
+			match: true,
+		},
+		{
+			name:  "matches allowed indexes in a different order",
+			spec:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "infra", "main"}},
+			live:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"main", "infra", "audit"}},
+			match: true,
+		},
+		{
+			name:  "matches default index added to allowed indexes by Splunk",
+			spec:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit"}},
+			live:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}},
+			match: true,
+		},
+		{
+			name:  "matches duplicate allowed indexes",
+			spec:  v1alpha1.SplunkTokenSpec{AllowedIndexes: []string{"audit", "audit"}},
+			live:  v1alpha1.SplunkTokenSpec{AllowedIndexes: []string{"audit"}},
+			match: true,
+		},
+		{
+			name:  "matches unmanaged sourcetype and description",
+			spec:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			live:  v1alpha1.SplunkTokenSpec{DefaultIndex: "main", Sourcetype: "json", Description: "set in Splunk"},
+			match: true,
+		},
+		{
+			name: "detects different default index",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "development", AllowedIndexes: []string{"main"}},
+		},
+		{
+			name: "detects missing allowed index",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "infra"}},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}},
+		},
+		{
+			name: "detects extra allowed index",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", AllowedIndexes: []string{"audit", "main"}},
+		},
+		{
+			name: "detects different sourcetype",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", Sourcetype: "json"},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", Sourcetype: "syslog"},
+		},
+		{
+			name: "detects disabled token",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", Disabled: true},
+		},
+		{
+			name: "detects different description",
+			spec: v1alpha1.SplunkTokenSpec{DefaultIndex: "main", Description: "cluster logs"},
+			live: v1alpha1.SplunkTokenSpec{DefaultIndex: "main"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if match := TokenMatchesSpec(&HECToken{Spec: tt.live}, tt.spec); match != tt.match {
+				t.Errorf("expected match %t but got %t", tt.match, match)
+			}
+		})
+	}
+}
+
+func TestTokenTagsMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  map[string]string
+		live  map[string]string
+		match bool
+	}{
+		{
+			name:  "matches identical tags",
+			tags:  map[string]string{"businessUnit": "security"},
+			live:  map[string]string{"businessUnit": "security"},
+			match: true,
+		},
+		{
+			name:  "matches any live tags when none are wanted",
+			live:  map[string]string{"businessUnit": "security"},
+			match: true,
+		},
+		{
+			name: "detects different tag value",
+			tags: map[string]string{"businessUnit": "security"},
+			live: map[string]string{"businessUnit": "sre"},
+		},
+		{
+			name: "detects missing tags",
+			tags: map[string]string{"businessUnit": "security"},
+		},
+		{
+			name: "detects extra live tag",
+			tags: map[string]string{"businessUnit": "security"},
+			live: map[string]string{"businessUnit": "security", "costCenter": "1234"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TokenTagsMatch(&HECToken{Tags: tt.live}, tt.tags); got != tt.match {
+				t.Errorf("expected match %t but got %t", tt.match, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package splunkapi
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// WithMinTLSVersion sets the oldest TLS version the Client accepts when connecting to Splunk,
+// such as tls.VersionTLS13. Zero uses the default of TLS 1.2, so connections never fall back
+// to older versions whatever the Go runtime allows.
+func WithMinTLSVersion(version uint16) ClientOption {
+	return func(c *Client) {
+		if version != 0 {
+			c.minTLSVersion = version
+		}
+	}
+}
+
+// newTransport returns a copy of the default HTTP transport that refuses TLS versions older
+// than minVersion.
+func newTransport(minVersion uint16) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: minVersion}
+	return transport
+}
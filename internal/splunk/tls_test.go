@@ -0,0 +1,73 @@
+package splunkapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMinTLSVersion(t *testing.T) {
+	tests := []struct {
+		name             string
+		serverMaxVersion uint16
+		clientMinVersion uint16
+		wantRefused      bool
+	}{
+		{
+			name:             "refuses TLS 1.1 by default",
+			serverMaxVersion: tls.VersionTLS11,
+			wantRefused:      true,
+		},
+		{
+			name:             "accepts TLS 1.2 by default",
+			serverMaxVersion: tls.VersionTLS12,
+		},
+		{
+			name:             "refuses TLS 1.2 when TLS 1.3 is required",
+			serverMaxVersion: tls.VersionTLS12,
+			clientMinVersion: tls.VersionTLS13,
+			wantRefused:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverCalls int
+			splunkServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				serverCalls++
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			splunkServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+			splunkServer.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tt.serverMaxVersion}
+			splunkServer.StartTLS()
+			defer splunkServer.Close()
+
+			testClient, err := NewClient("mock_splunk", "foo", WithMinTLSVersion(tt.clientMinVersion))
+			if err != nil {
+				t.Fatalf("unexpected error creating client: %s", err)
+			}
+			testClient.url = strings.Replace(testClient.url, acsHostname, splunkServer.URL, 1)
+			roots := x509.NewCertPool()
+			roots.AddCert(splunkServer.Certificate())
+			testClient.client.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+
+			err = testClient.DeleteToken(t.Context(), "bar")
+			if tt.wantRefused {
+				if err == nil || !strings.Contains(err.Error(), "protocol version") {
+					t.Errorf("expected TLS handshake to be refused but got %v", err)
+				}
+				if serverCalls != 0 {
+					t.Error("expected no request to reach the server")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected TLS handshake to succeed but got %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+// splunktokenlog logs the admission decisions of the SplunkToken webhook.
+var splunktokenlog = logf.Log.WithName("splunktoken-resource")
+
+// garbageCollectors are the users that delete SplunkTokens when their owner or namespace is deleted.
+var garbageCollectors = []string{
+	"system:serviceaccount:kube-system:generic-garbage-collector",
+	"system:serviceaccount:kube-system:namespace-controller",
+}
+
+// SetupSplunkTokenWebhookWithManager registers the webhook for SplunkToken in the manager.
+func SetupSplunkTokenWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&stv1alpha1.SplunkToken{}).
+		WithValidator(&SplunkTokenCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-splunktoken-managed-openshift-io-v1alpha1-splunktoken,mutating=false,failurePolicy=fail,sideEffects=None,groups=splunktoken.managed.openshift.io,resources=splunktokens,verbs=delete,versions=v1alpha1,name=vsplunktoken-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SplunkTokenCustomValidator protects SplunkTokens from accidental deletion.
+// A SplunkToken can only be deleted if it has the allow-delete annotation set to "true",
+// or if the deletion comes from garbage collection of its owner or namespace.
+type SplunkTokenCustomValidator struct{}
+
+var _ webhook.CustomValidator = &SplunkTokenCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator. Creation is not restricted.
+func (v *SplunkTokenCustomValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator. Updates are not restricted.
+func (v *SplunkTokenCustomValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type SplunkToken.
+func (v *SplunkTokenCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	splunktoken, ok := obj.(*stv1alpha1.SplunkToken)
+	if !ok {
+		return nil, fmt.Errorf("expected a SplunkToken object but got %T", obj)
+	}
+	splunktokenlog.Info("validation for SplunkToken upon deletion", "namespace", splunktoken.GetNamespace(), "name", splunktoken.GetName())
+
+	if splunktoken.Annotations[config.AllowDeleteAnnotation] == "true" {
+		return nil, nil
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && slices.Contains(garbageCollectors, req.UserInfo.Username) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("deletion of SplunkToken %s/%s is blocked to protect log shipping, set annotation %s=true to allow it",
+		splunktoken.Namespace, splunktoken.Name, config.AllowDeleteAnnotation)
+}
@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	stv1alpha1 "github.com/openshift/splunk-token-operator/api/v1alpha1"
+	"github.com/openshift/splunk-token-operator/config"
+)
+
+func TestValidateDelete(t *testing.T) {
+	validator := SplunkTokenCustomValidator{}
+
+	withUser := func(username string) context.Context {
+		return admission.NewContextWithRequest(t.Context(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	t.Run("blocks deletion without annotation", func(t *testing.T) {
+		token := testSplunkToken(nil)
+		if _, err := validator.ValidateDelete(withUser("kube:admin"), &token); err == nil {
+			t.Error("expected deletion to be blocked")
+		}
+	})
+
+	t.Run("blocks deletion with annotation not set to true", func(t *testing.T) {
+		token := testSplunkToken(map[string]string{config.AllowDeleteAnnotation: "yes"})
+		if _, err := validator.ValidateDelete(withUser("kube:admin"), &token); err == nil {
+			t.Error("expected deletion to be blocked")
+		}
+	})
+
+	t.Run("allows deletion with annotation", func(t *testing.T) {
+		token := testSplunkToken(map[string]string{config.AllowDeleteAnnotation: "true"})
+		if _, err := validator.ValidateDelete(withUser("kube:admin"), &token); err != nil {
+			t.Errorf("expected deletion to be allowed but got %s", err)
+		}
+	})
+
+	t.Run("allows deletion by owner garbage collection", func(t *testing.T) {
+		token := testSplunkToken(nil)
+		ctx := withUser("system:serviceaccount:kube-system:generic-garbage-collector")
+		if _, err := validator.ValidateDelete(ctx, &token); err != nil {
+			t.Errorf("expected deletion to be allowed but got %s", err)
+		}
+	})
+}
+
+func testSplunkToken(annotations map[string]string) stv1alpha1.SplunkToken {
+	return stv1alpha1.SplunkToken{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "test-namespace",
+			Name:        "cluster",
+			Annotations: annotations,
+		},
+		Spec: stv1alpha1.SplunkTokenSpec{
+			Name: "<internal-cluster-id>",
+		},
+	}
+}